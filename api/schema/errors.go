@@ -0,0 +1,28 @@
+package schema
+
+// Error codes returned in the `code` field of error responses, clients should branch on these
+// rather than on the message
+const (
+	ErrCodeBadRequest          = "bad_request"
+	ErrCodeInvalidBody         = "invalid_body"
+	ErrCodeValidationFailed    = "validation_failed"
+	ErrCodeUnauthorized        = "unauthorized"
+	ErrCodeNotAllowed          = "not_allowed"
+	ErrCodeNotFound            = "not_found"
+	ErrCodeRoomNotFound        = "room_not_found"
+	ErrCodeParticipantNotFound = "participant_not_found"
+	ErrCodeStoryNotFound       = "story_not_found"
+	ErrCodeWebhookNotFound     = "webhook_not_found"
+	ErrCodeTeamNotFound        = "team_not_found"
+	ErrCodeConflict            = "conflict"
+	ErrCodeGone                = "gone"
+	ErrCodeParticipantExists   = "participant_exists"
+	ErrCodeTeamExists          = "team_exists"
+	ErrCodeRoomFull            = "room_full"
+	ErrCodeRoundFinalized      = "round_finalized"
+	ErrCodeRateLimited         = "rate_limited"
+	ErrCodeTrackerNotConnected = "tracker_not_connected"
+	ErrCodeTrackerFailed       = "tracker_failed"
+	ErrCodeChatNotConnected    = "chat_not_connected"
+	ErrCodeInternal            = "internal_error"
+)
@@ -3,7 +3,7 @@ package schema
 import "github.com/jponc/estimatex-serverless/internal/types"
 
 type SayHelloRequest struct {
-	Name string `json:"name"`
+	Name string `json:"name" validate:"trim,required,max=32"`
 }
 
 type SayHelloResponse struct {
@@ -11,7 +11,14 @@ type SayHelloResponse struct {
 }
 
 type HostRoomRequest struct {
-	Name string `json:"name"`
+	Name string `json:"name" validate:"trim,required,max=32,pattern=name"`
+	// Deck is optional, rooms use the team's deck or types.DefaultDeck without one
+	Deck []string `json:"deck" validate:"max=20"`
+	// TeamSlug opens the room as the team's current session, it needs the team's key
+	TeamSlug string `json:"team_slug" validate:"trim,max=40,pattern=team_slug"`
+	TeamKey  string `json:"team_key" validate:"trim,max=128"`
+	// UserToken is optional, it hosts as the signed in user
+	UserToken string `json:"user_token" validate:"trim,max=2048"`
 }
 
 type HostRoomResponse struct {
@@ -23,20 +30,253 @@ type FindRoomResponse struct {
 	types.Room
 }
 
+// JoinRoomRequest joins RoomID, or the current session of the team with TeamSlug. Team members
+// with the admin role join as admins when they give the team's key or sign in as the member's
+// user with UserToken.
 type JoinRoomRequest struct {
-	RoomID string `json:"room_id"`
-	Name   string `json:"name"`
+	RoomID    string `json:"room_id" validate:"trim,pattern=room_id"`
+	TeamSlug  string `json:"team_slug" validate:"trim,max=40,pattern=team_slug"`
+	TeamKey   string `json:"team_key" validate:"trim,max=128"`
+	Name      string `json:"name" validate:"trim,required,max=32,pattern=name"`
+	UserToken string `json:"user_token" validate:"trim,max=2048"`
 }
 
 type JoinRoomResponse struct {
+	RoomID      string `json:"room_id"`
 	AccessToken string `json:"access_token"`
 }
 
+// LoginRequest signs in with an ID token the client got from the identity provider
+type LoginRequest struct {
+	IDToken string `json:"id_token" validate:"trim,required,max=8192"`
+}
+
+// LoginResponse holds the user token HostRoom and JoinRoom take to join as the user
+type LoginResponse struct {
+	User      types.User `json:"user"`
+	UserToken string     `json:"user_token"`
+}
+
+type TeamMemberRequest struct {
+	Name   string `json:"name" validate:"trim,required,max=32,pattern=name"`
+	Role   string `json:"role" validate:"trim,required,pattern=role"`
+	UserID string `json:"user_id" validate:"trim,pattern=user_id"`
+}
+
+// CreateTeamRequest reserves Slug for the team's recurring sessions, members are validated like
+// TeamMemberRequest
+type CreateTeamRequest struct {
+	Slug        string              `json:"slug" validate:"trim,required,min=3,max=40,pattern=team_slug"`
+	Name        string              `json:"name" validate:"trim,required,max=64"`
+	Deck        []string            `json:"deck" validate:"max=20"`
+	DefaultRole string              `json:"default_role" validate:"trim,pattern=role"`
+	Members     []TeamMemberRequest `json:"members" validate:"max=50"`
+}
+
+// CreateTeamResponse holds the team's key, it's only ever shown here
+type CreateTeamResponse struct {
+	Team    types.Team `json:"team"`
+	TeamKey string     `json:"team_key"`
+}
+
+// UpdateTeamRequest replaces the team's name and session defaults
+type UpdateTeamRequest struct {
+	Slug        string              `json:"slug" validate:"trim,required,max=40,pattern=team_slug"`
+	TeamKey     string              `json:"team_key" validate:"trim,required,max=128"`
+	Name        string              `json:"name" validate:"trim,required,max=64"`
+	Deck        []string            `json:"deck" validate:"max=20"`
+	DefaultRole string              `json:"default_role" validate:"trim,pattern=role"`
+	Members     []TeamMemberRequest `json:"members" validate:"max=50"`
+}
+
+type UpdateTeamResponse struct {
+	Team types.Team `json:"team"`
+}
+
+type FindTeamRequest struct {
+	Slug string `json:"slug" validate:"trim,required,max=40,pattern=team_slug"`
+}
+
+type FindTeamResponse struct {
+	Team types.Team `json:"team"`
+}
+
+// FindTeamHistoryResponse is the latest sessions and revealed rounds of the caller's room's team
+type FindTeamHistoryResponse struct {
+	Team     types.Team    `json:"team"`
+	Sessions []types.Room  `json:"sessions"`
+	Rounds   []types.Round `json:"rounds"`
+}
+
 type CastVoteRequest struct {
-	Vote string `json:"vote"`
+	Vote string `json:"vote" validate:"trim,required,max=16"`
+	// Comment is an optional rationale, hidden from the other participants until votes are revealed
+	Comment string `json:"comment" validate:"trim,max=280"`
 }
 
 type CastVoteResponse struct{}
 
 type RevealVotesResponse struct{}
 type ResetVotesResponse struct{}
+type RevoteRoundResponse struct{}
+
+type AddStoryRequest struct {
+	Title       string `json:"title" validate:"trim,required,max=200"`
+	Description string `json:"description" validate:"trim,max=4000"`
+	ExternalKey string `json:"external_key" validate:"trim,max=64"`
+	URL         string `json:"url" validate:"trim,max=2048,pattern=url"`
+}
+
+type AddStoryResponse struct {
+	Story types.Story `json:"story"`
+}
+
+// ImportStoriesRequest adds Stories to the end of the queue in order, each is validated like AddStoryRequest
+type ImportStoriesRequest struct {
+	Stories []AddStoryRequest `json:"stories" validate:"required,max=20"`
+}
+
+type ImportStoriesResponse struct {
+	Stories []types.Story `json:"stories"`
+}
+
+// RegisterWebhookRequest subscribes URL to the room's events, only EventTypes when it isn't empty
+type RegisterWebhookRequest struct {
+	URL        string   `json:"url" validate:"trim,required,max=2048,pattern=https_url"`
+	EventTypes []string `json:"event_types"`
+}
+
+// RegisterWebhookResponse has the secret deliveries are signed with, it isn't shown again
+type RegisterWebhookResponse struct {
+	Webhook types.Webhook `json:"webhook"`
+	Secret  string        `json:"secret"`
+}
+
+type RemoveWebhookRequest struct {
+	WebhookID string `json:"webhook_id" validate:"trim,required"`
+}
+
+type RemoveWebhookResponse struct{}
+
+type FindWebhooksResponse struct {
+	Webhooks []types.Webhook `json:"webhooks"`
+	// Deliveries are the most recent deliveries to any of the webhooks, newest first
+	Deliveries []types.WebhookDelivery `json:"deliveries"`
+}
+
+// ConnectChatRequest posts the room's round results to a Slack or Teams incoming webhook
+type ConnectChatRequest struct {
+	Kind       string `json:"kind" validate:"trim,required,pattern=chat"`
+	WebhookURL string `json:"webhook_url" validate:"trim,required,max=2048,pattern=https_url"`
+}
+
+type ConnectChatResponse struct {
+	Integration types.ChatIntegration `json:"integration"`
+}
+
+type DisconnectChatResponse struct{}
+
+// ConnectTrackerRequest connects the room to Project in a Jira site or GitHub repo. Username is
+// the Jira account email, Token its API token or a GitHub token.
+type ConnectTrackerRequest struct {
+	Kind        string `json:"kind" validate:"trim,required,pattern=tracker"`
+	BaseURL     string `json:"base_url" validate:"trim,max=2048,pattern=https_url"`
+	Project     string `json:"project" validate:"trim,required,max=200"`
+	PointsField string `json:"points_field" validate:"trim,max=64"`
+	Username    string `json:"username" validate:"trim,max=200"`
+	Token       string `json:"token" validate:"trim,required,max=4096"`
+}
+
+type ConnectTrackerResponse struct {
+	Integration types.TrackerIntegration `json:"integration"`
+}
+
+// SearchTrackerIssuesRequest searches the connected project, Query is JQL for Jira and GitHub
+// search syntax for GitHub
+type SearchTrackerIssuesRequest struct {
+	Query string `json:"query" validate:"trim,max=500"`
+}
+
+type SearchTrackerIssuesResponse struct {
+	Issues []types.TrackerIssue `json:"issues"`
+}
+
+// ImportBacklogResponse lists the stories added to the queue and the rows that couldn't be written
+type ImportBacklogResponse struct {
+	Stories []types.Story    `json:"stories"`
+	Failed  []ImportRowError `json:"failed"`
+}
+
+// ImportRowError is why row of the imported backlog, counted from 1 after any header, was rejected
+type ImportRowError struct {
+	Row     int    `json:"row"`
+	Message string `json:"message"`
+}
+
+// ReorderStoriesRequest lists every queued story ID in its new order
+type ReorderStoriesRequest struct {
+	StoryIDs []string `json:"story_ids" validate:"required"`
+}
+
+type ReorderStoriesResponse struct{}
+
+type RemoveStoryRequest struct {
+	StoryID string `json:"story_id" validate:"trim,required"`
+}
+
+type RemoveStoryResponse struct{}
+
+type NextStoryResponse struct {
+	// Story is nil when the queue was empty
+	Story *types.Story `json:"story"`
+	Round int          `json:"round"`
+}
+
+type FindStoriesResponse struct {
+	CurrentStory *types.Story  `json:"current_story"`
+	Queue        []types.Story `json:"queue"`
+}
+
+type SetFinalEstimateRequest struct {
+	Estimate string `json:"estimate" validate:"trim,required,max=16"`
+	// Override allows an estimate that isn't one of the room's cards
+	Override bool `json:"override"`
+}
+
+type SetFinalEstimateResponse struct {
+	Round types.Round `json:"round"`
+}
+
+type FindRoundsResponse struct {
+	Rounds []types.Round `json:"rounds"`
+}
+
+type KickParticipantRequest struct {
+	Name string `json:"name" validate:"trim,required,max=32"`
+}
+
+type KickParticipantResponse struct{}
+
+type RenameParticipantRequest struct {
+	Name string `json:"name" validate:"trim,required,max=32,pattern=name"`
+}
+
+type RenameParticipantResponse struct {
+	AccessToken string `json:"access_token"`
+}
+
+// AnalyticsResponse covers a page of sessions, NextCursor fetches the next older page and is empty
+// on the last one
+type AnalyticsResponse struct {
+	types.Analytics
+	TeamSlug   string `json:"team_slug,omitempty"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// GetRoomEventsResponse is a page of events, when HasMore is set the next page is fetched with
+// NextSince as `since`
+type GetRoomEventsResponse struct {
+	Events    []Event `json:"events"`
+	HasMore   bool    `json:"has_more"`
+	NextSince int64   `json:"next_since,omitempty"`
+}
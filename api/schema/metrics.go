@@ -0,0 +1,4 @@
+package schema
+
+// MetricsNamespace is the CloudWatch namespace every Lambda records its metrics under
+const MetricsNamespace = "EstimateX"
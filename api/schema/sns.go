@@ -1,27 +1,151 @@
 package schema
 
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jponc/estimatex-serverless/internal/types"
+)
+
+// RoomEventsTopic is the single SNS topic every room event is published to
+const RoomEventsTopic = "RoomEvents"
+
+// EventVersion is the current version of the Event envelope
+const EventVersion = 1
+
+// CorrelationIDAttribute is the SNS message attribute carrying the event's correlation ID
+const CorrelationIDAttribute = "correlation_id"
+
 const (
-	ParticipantJoined string = "ParticipantJoined"
-	ParticipantVoted  string = "ParticipantVoted"
-	RevealVotes       string = "RevealVotes"
-	ResetVotes        string = "ResetVotes"
+	ParticipantJoined  string = "ParticipantJoined"
+	ParticipantVoted   string = "ParticipantVoted"
+	RevealVotes        string = "RevealVotes"
+	ResetVotes         string = "ResetVotes"
+	ParticipantKicked  string = "ParticipantKicked"
+	ParticipantRenamed string = "ParticipantRenamed"
+	EstimateFinalized  string = "EstimateFinalized"
+	StoryQueueChanged  string = "StoryQueueChanged"
+	StoryChanged       string = "StoryChanged"
 )
 
+// EventTypes lists every event type, add new types here so webhooks can subscribe to them
+var EventTypes = []string{
+	ParticipantJoined,
+	ParticipantVoted,
+	RevealVotes,
+	ResetVotes,
+	ParticipantKicked,
+	ParticipantRenamed,
+	EstimateFinalized,
+	StoryQueueChanged,
+	StoryChanged,
+}
+
+// Event is the envelope every room event is wrapped in, Payload holds one of the messages below.
+// ID is unique per event so consumers can drop redeliveries, Sequence increases by one per event
+// within a room so clients can detect gaps and resync from the room snapshot. CorrelationID links
+// the event to the request that caused it.
+type Event struct {
+	ID            string          `json:"id"`
+	Type          string          `json:"type"`
+	Version       int             `json:"version"`
+	RoomID        string          `json:"room_id"`
+	Sequence      int64           `json:"sequence"`
+	Timestamp     time.Time       `json:"timestamp"`
+	CorrelationID string          `json:"correlation_id,omitempty"`
+	Payload       json.RawMessage `json:"payload"`
+}
+
+// NewEvent wraps payload in a new Event envelope
+func NewEvent(eventType, roomID string, sequence int64, payload interface{}) (*Event, error) {
+	id, err := newEventID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate event ID: %v", err)
+	}
+
+	p, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal event payload: %v", err)
+	}
+
+	e := &Event{
+		ID:        id,
+		Type:      eventType,
+		Version:   EventVersion,
+		RoomID:    roomID,
+		Sequence:  sequence,
+		Timestamp: time.Now(),
+		Payload:   p,
+	}
+
+	return e, nil
+}
+
+// UnmarshalPayload unmarshals the event payload into v
+func (e *Event) UnmarshalPayload(v interface{}) error {
+	return json.Unmarshal(e.Payload, v)
+}
+
+func newEventID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
 type ParticipantJoinedMessage struct {
-	RoomID          string `json:"room_id"`
 	ParticipantName string `json:"participant_name"`
+	IsAdmin         bool   `json:"is_admin"`
+	// UserID is the signed in user joining, empty for anonymous participants
+	UserID string `json:"user_id,omitempty"`
 }
 
 type ParticipantVotedMessage struct {
-	RoomID          string `json:"room_id"`
 	ParticipantName string `json:"participant_name"`
 	Vote            string `json:"vote"`
 }
 
 type RevealVotesMessage struct {
-	RoomID string `json:"room_id"`
+	Round int `json:"round,omitempty"`
+	// Stats carry the participants' comments, votes are logged without them so they stay hidden
+	// until the reveal
+	Stats types.RoundStats `json:"stats"`
+	// Comparison is set when the revealed round re-votes an earlier one
+	Comparison *types.RoundComparison `json:"comparison,omitempty"`
+}
+
+type EstimateFinalizedMessage struct {
+	Round    int    `json:"round"`
+	Estimate string `json:"estimate"`
+	Override bool   `json:"override"`
+}
+
+type StoryQueueChangedMessage struct {
+	StoryIDs []string `json:"story_ids"`
 }
 
+// StoryChangedMessage starts Round on Story, Story is nil once the queue has run out
+type StoryChangedMessage struct {
+	Story *types.Story `json:"story"`
+	Round int          `json:"round"`
+}
+
+// ResetVotesMessage starts Round, a re-vote of ParentRound when it's set
 type ResetVotesMessage struct {
-	RoomID string `json:"room_id"`
+	Round       int `json:"round,omitempty"`
+	ParentRound int `json:"parent_round,omitempty"`
+}
+
+type ParticipantKickedMessage struct {
+	ParticipantName string `json:"participant_name"`
+}
+
+type ParticipantRenamedMessage struct {
+	ParticipantName    string `json:"participant_name"`
+	NewParticipantName string `json:"new_participant_name"`
 }
@@ -0,0 +1,26 @@
+package schema
+
+import "regexp"
+
+// ValidationPatterns are the named patterns request fields are checked against with `pattern=<name>`
+var ValidationPatterns = map[string]*regexp.Regexp{
+	// Letters, digits, spaces and a little punctuation, starting with a letter or digit
+	"name": regexp.MustCompile(`^[\p{L}\p{N}][\p{L}\p{N} _.'-]*$`),
+	// Character codes from any configured alphanumeric alphabet, including the mixed case codes of
+	// older rooms, or dash separated word codes
+	"room_id": regexp.MustCompile(`^([a-zA-Z0-9]{4,32}|[a-z]+(-[a-z]+){1,7})$`),
+	// Absolute http(s) links, e.g. to the story in the team's tracker
+	"url": regexp.MustCompile(`^https?://\S+$`),
+	// Absolute https links, webhooks are only delivered over TLS
+	"https_url": regexp.MustCompile(`^https://\S+$`),
+	// Issue trackers a room can connect to
+	"tracker": regexp.MustCompile(`^(jira|github)$`),
+	// Chat apps round results can be posted to
+	"chat": regexp.MustCompile(`^(slack|teams)$`),
+	// Lowercase dash separated team slugs, e.g. platform-squad
+	"team_slug": regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`),
+	// Roles team members join sessions with
+	"role": regexp.MustCompile(`^(admin|voter)$`),
+	// IDs of signed in users
+	"user_id": regexp.MustCompile(`^[a-f0-9]{32}$`),
+}
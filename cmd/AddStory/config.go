@@ -3,43 +3,37 @@ package main
 import (
 	"fmt"
 	"os"
+	"strings"
 )
 
 // Config
 type Config struct {
-	PusherAppID   string
-	PusherKey     string
-	PusherSecret  string
-	PusherCluster string
+	AWSRegion      string
+	DBTableName    string
+	AllowedOrigins []string
 }
 
 // NewConfig initialises a new config
 func NewConfig() (*Config, error) {
-	appID, err := getEnv("PUSHER_APP_ID")
+	awsRegion, err := getEnv("AWS_REGION")
 	if err != nil {
 		return nil, err
 	}
 
-	key, err := getEnv("PUSHER_KEY")
+	dbTableName, err := getEnv("DB_TABLE_NAME")
 	if err != nil {
 		return nil, err
 	}
 
-	secret, err := getEnv("PUSHER_SECRET")
-	if err != nil {
-		return nil, err
-	}
-
-	cluster, err := getEnv("PUSHER_CLUSTER")
+	allowedOrigins, err := getEnv("ALLOWED_ORIGINS")
 	if err != nil {
 		return nil, err
 	}
 
 	return &Config{
-		PusherAppID:   appID,
-		PusherKey:     key,
-		PusherSecret:  secret,
-		PusherCluster: cluster,
+		AWSRegion:      awsRegion,
+		DBTableName:    dbTableName,
+		AllowedOrigins: strings.Split(allowedOrigins, ","),
 	}, nil
 }
 
@@ -1,15 +1,18 @@
 package main
 
 import (
-	"log"
+	log "github.com/sirupsen/logrus"
 
 	"github.com/aws/aws-lambda-go/lambda"
 
 	"github.com/jponc/estimatex-serverless/internal/auth"
 	"github.com/jponc/estimatex-serverless/internal/authoriser"
+	"github.com/jponc/estimatex-serverless/pkg/logger"
 )
 
 func main() {
+	logger.Init()
+
 	config, err := NewConfig()
 	if err != nil {
 		log.Fatalf("cannot initialise config %v", err)
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Config
+type Config struct {
+	AWSRegion      string
+	DBTableName    string
+	AllowedOrigins []string
+	// IntegrationsParameterPrefix is the Parameter Store path tracker credentials are kept under
+	IntegrationsParameterPrefix string
+}
+
+// NewConfig initialises a new config
+func NewConfig() (*Config, error) {
+	awsRegion, err := getEnv("AWS_REGION")
+	if err != nil {
+		return nil, err
+	}
+
+	dbTableName, err := getEnv("DB_TABLE_NAME")
+	if err != nil {
+		return nil, err
+	}
+
+	allowedOrigins, err := getEnv("ALLOWED_ORIGINS")
+	if err != nil {
+		return nil, err
+	}
+
+	integrationsParameterPrefix, err := getEnv("INTEGRATIONS_PARAMETER_PREFIX")
+	if err != nil {
+		return nil, err
+	}
+
+	return &Config{
+		AWSRegion:                   awsRegion,
+		DBTableName:                 dbTableName,
+		AllowedOrigins:              strings.Split(allowedOrigins, ","),
+		IntegrationsParameterPrefix: integrationsParameterPrefix,
+	}, nil
+}
+
+func getEnv(key string) (string, error) {
+	v := os.Getenv(key)
+
+	if v == "" {
+		return "", fmt.Errorf("%s environment variable missing", key)
+	}
+
+	return v, nil
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	log "github.com/sirupsen/logrus"
+
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/jponc/estimatex-serverless/api/schema"
+	"github.com/jponc/estimatex-serverless/internal/api"
+	"github.com/jponc/estimatex-serverless/internal/delivery"
+	"github.com/jponc/estimatex-serverless/internal/integrations"
+	"github.com/jponc/estimatex-serverless/internal/repository/ddbrepository"
+	"github.com/jponc/estimatex-serverless/pkg/dynamodb"
+	"github.com/jponc/estimatex-serverless/pkg/lambdaresponses"
+	"github.com/jponc/estimatex-serverless/pkg/logger"
+	"github.com/jponc/estimatex-serverless/pkg/metrics"
+	"github.com/jponc/estimatex-serverless/pkg/ssm"
+)
+
+func main() {
+	logger.Init()
+
+	config, err := NewConfig()
+	if err != nil {
+		log.Fatalf("cannot initialise config %v", err)
+	}
+
+	dynamodbClient, err := dynamodb.NewClient(config.AWSRegion, config.DBTableName)
+	if err != nil {
+		log.Fatalf("cannot initialise dynamodb client %v", err)
+	}
+
+	ddbrepository, err := ddbrepository.NewClient(dynamodbClient)
+	if err != nil {
+		log.Fatalf("cannot initialise ddbrepository %v", err)
+	}
+
+	ssmClient, err := ssm.NewClient(config.AWSRegion)
+	if err != nil {
+		log.Fatalf("cannot initialise ssm client %v", err)
+	}
+
+	trackers := integrations.NewConnector(ddbrepository, ssmClient, config.IntegrationsParameterPrefix, delivery.NewHTTPClient())
+
+	corsPolicy := lambdaresponses.NewCORSPolicy(config.AllowedOrigins)
+
+	metricsRecorder := metrics.NewRecorder(schema.MetricsNamespace, nil)
+
+	service := api.NewService(api.Options{
+		Repository: ddbrepository,
+		CORSPolicy: corsPolicy,
+		Metrics:    metricsRecorder,
+		Trackers:   trackers,
+	})
+	lambda.Start(service.ConnectTracker)
+}
@@ -4,12 +4,18 @@ import (
 	log "github.com/sirupsen/logrus"
 
 	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/jponc/estimatex-serverless/api/schema"
 	"github.com/jponc/estimatex-serverless/internal/api"
 	"github.com/jponc/estimatex-serverless/internal/repository/ddbrepository"
 	"github.com/jponc/estimatex-serverless/pkg/dynamodb"
+	"github.com/jponc/estimatex-serverless/pkg/lambdaresponses"
+	"github.com/jponc/estimatex-serverless/pkg/logger"
+	"github.com/jponc/estimatex-serverless/pkg/metrics"
 )
 
 func main() {
+	logger.Init()
+
 	config, err := NewConfig()
 	if err != nil {
 		log.Fatalf("cannot initialise config %v", err)
@@ -25,6 +31,14 @@ func main() {
 		log.Fatalf("cannot initialise ddbrepository %v", err)
 	}
 
-	service := api.NewService(ddbrepository, nil, nil)
+	corsPolicy := lambdaresponses.NewCORSPolicy(config.AllowedOrigins)
+
+	metricsRecorder := metrics.NewRecorder(schema.MetricsNamespace, nil)
+
+	service := api.NewService(api.Options{
+		Repository: ddbrepository,
+		CORSPolicy: corsPolicy,
+		Metrics:    metricsRecorder,
+	})
 	lambda.Start(service.FindParticipants)
 }
@@ -3,12 +3,14 @@ package main
 import (
 	"fmt"
 	"os"
+	"strings"
 )
 
 // Config
 type Config struct {
-	AWSRegion   string
-	DBTableName string
+	AWSRegion      string
+	DBTableName    string
+	AllowedOrigins []string
 }
 
 // NewConfig initialises a new config
@@ -23,9 +25,15 @@ func NewConfig() (*Config, error) {
 		return nil, err
 	}
 
+	allowedOrigins, err := getEnv("ALLOWED_ORIGINS")
+	if err != nil {
+		return nil, err
+	}
+
 	return &Config{
-		AWSRegion:   awsRegion,
-		DBTableName: dbTableName,
+		AWSRegion:      awsRegion,
+		DBTableName:    dbTableName,
+		AllowedOrigins: strings.Split(allowedOrigins, ","),
 	}, nil
 }
 
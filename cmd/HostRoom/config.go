@@ -3,13 +3,21 @@ package main
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
+
+	"github.com/jponc/estimatex-serverless/internal/roomid"
 )
 
 // Config
 type Config struct {
-	AWSRegion   string
-	DBTableName string
-	JWTSecret   string
+	AWSRegion      string
+	DBTableName    string
+	JWTSecret      string
+	AllowedOrigins []string
+	RoomIDAlphabet string
+	RoomIDLength   int
+	RoomIDWords    int
 }
 
 // NewConfig initialises a new config
@@ -29,10 +37,30 @@ func NewConfig() (*Config, error) {
 		return nil, err
 	}
 
+	allowedOrigins, err := getEnv("ALLOWED_ORIGINS")
+	if err != nil {
+		return nil, err
+	}
+
+	roomIDLength, err := getIntEnv("ROOM_ID_LENGTH", roomid.DefaultLength)
+	if err != nil {
+		return nil, err
+	}
+
+	// Word based codes are used instead of the alphabet when ROOM_ID_WORDS is set
+	roomIDWords, err := getIntEnv("ROOM_ID_WORDS", 0)
+	if err != nil {
+		return nil, err
+	}
+
 	return &Config{
-		AWSRegion:   awsRegion,
-		DBTableName: dbTableName,
-		JWTSecret:   jwtSecret,
+		AWSRegion:      awsRegion,
+		DBTableName:    dbTableName,
+		JWTSecret:      jwtSecret,
+		AllowedOrigins: strings.Split(allowedOrigins, ","),
+		RoomIDAlphabet: getEnvOrDefault("ROOM_ID_ALPHABET", roomid.DefaultAlphabet),
+		RoomIDLength:   roomIDLength,
+		RoomIDWords:    roomIDWords,
 	}, nil
 }
 
@@ -45,3 +73,28 @@ func getEnv(key string) (string, error) {
 
 	return v, nil
 }
+
+func getEnvOrDefault(key, fallback string) string {
+	v := os.Getenv(key)
+
+	if v == "" {
+		return fallback
+	}
+
+	return v
+}
+
+func getIntEnv(key string, fallback int) (int, error) {
+	v := os.Getenv(key)
+
+	if v == "" {
+		return fallback, nil
+	}
+
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, fmt.Errorf("%s environment variable must be a number: %v", key, err)
+	}
+
+	return n, nil
+}
@@ -4,13 +4,20 @@ import (
 	log "github.com/sirupsen/logrus"
 
 	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/jponc/estimatex-serverless/api/schema"
 	"github.com/jponc/estimatex-serverless/internal/api"
 	"github.com/jponc/estimatex-serverless/internal/auth"
 	"github.com/jponc/estimatex-serverless/internal/repository/ddbrepository"
+	"github.com/jponc/estimatex-serverless/internal/roomid"
 	"github.com/jponc/estimatex-serverless/pkg/dynamodb"
+	"github.com/jponc/estimatex-serverless/pkg/lambdaresponses"
+	"github.com/jponc/estimatex-serverless/pkg/logger"
+	"github.com/jponc/estimatex-serverless/pkg/metrics"
 )
 
 func main() {
+	logger.Init()
+
 	config, err := NewConfig()
 	if err != nil {
 		log.Fatalf("cannot initialise config %v", err)
@@ -31,6 +38,26 @@ func main() {
 		log.Fatalf("cannot initialise ddbrepository %v", err)
 	}
 
-	service := api.NewService(ddbrepository, nil, authClient)
+	var roomIDs *roomid.Generator
+	if config.RoomIDWords > 0 {
+		roomIDs, err = roomid.NewWordGenerator(roomid.Words, config.RoomIDWords)
+	} else {
+		roomIDs, err = roomid.NewGenerator(config.RoomIDAlphabet, config.RoomIDLength)
+	}
+	if err != nil {
+		log.Fatalf("cannot initialise room ID generator %v", err)
+	}
+
+	corsPolicy := lambdaresponses.NewCORSPolicy(config.AllowedOrigins)
+
+	metricsRecorder := metrics.NewRecorder(schema.MetricsNamespace, nil)
+
+	service := api.NewService(api.Options{
+		Repository: ddbrepository,
+		AuthClient: authClient,
+		RoomIDs:    roomIDs,
+		CORSPolicy: corsPolicy,
+		Metrics:    metricsRecorder,
+	})
 	lambda.Start(service.HostRoom)
 }
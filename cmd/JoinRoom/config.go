@@ -3,14 +3,15 @@ package main
 import (
 	"fmt"
 	"os"
+	"strings"
 )
 
 // Config
 type Config struct {
-	AWSRegion   string
-	DBTableName string
-	JWTSecret   string
-	SNSPrefix   string
+	AWSRegion      string
+	DBTableName    string
+	JWTSecret      string
+	AllowedOrigins []string
 }
 
 // NewConfig initialises a new config
@@ -30,16 +31,16 @@ func NewConfig() (*Config, error) {
 		return nil, err
 	}
 
-	snsPrefix, err := getEnv("SNS_PREFIX")
+	allowedOrigins, err := getEnv("ALLOWED_ORIGINS")
 	if err != nil {
 		return nil, err
 	}
 
 	return &Config{
-		AWSRegion:   awsRegion,
-		DBTableName: dbTableName,
-		JWTSecret:   jwtSecret,
-		SNSPrefix:   snsPrefix,
+		AWSRegion:      awsRegion,
+		DBTableName:    dbTableName,
+		JWTSecret:      jwtSecret,
+		AllowedOrigins: strings.Split(allowedOrigins, ","),
 	}, nil
 }
 
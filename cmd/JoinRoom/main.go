@@ -4,14 +4,19 @@ import (
 	log "github.com/sirupsen/logrus"
 
 	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/jponc/estimatex-serverless/api/schema"
 	"github.com/jponc/estimatex-serverless/internal/api"
 	"github.com/jponc/estimatex-serverless/internal/auth"
 	"github.com/jponc/estimatex-serverless/internal/repository/ddbrepository"
 	"github.com/jponc/estimatex-serverless/pkg/dynamodb"
-	"github.com/jponc/estimatex-serverless/pkg/sns"
+	"github.com/jponc/estimatex-serverless/pkg/lambdaresponses"
+	"github.com/jponc/estimatex-serverless/pkg/logger"
+	"github.com/jponc/estimatex-serverless/pkg/metrics"
 )
 
 func main() {
+	logger.Init()
+
 	config, err := NewConfig()
 	if err != nil {
 		log.Fatalf("cannot initialise config %v", err)
@@ -32,11 +37,15 @@ func main() {
 		log.Fatalf("cannot initialise ddbrepository %v", err)
 	}
 
-	snsClient, err := sns.NewClient(config.AWSRegion, config.SNSPrefix)
-	if err != nil {
-		log.Fatalf("cannot initialise sns client %v", err)
-	}
+	corsPolicy := lambdaresponses.NewCORSPolicy(config.AllowedOrigins)
+
+	metricsRecorder := metrics.NewRecorder(schema.MetricsNamespace, nil)
 
-	service := api.NewService(ddbrepository, snsClient, authClient)
+	service := api.NewService(api.Options{
+		Repository: ddbrepository,
+		AuthClient: authClient,
+		CORSPolicy: corsPolicy,
+		Metrics:    metricsRecorder,
+	})
 	lambda.Start(service.JoinRoom)
 }
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Config
+type Config struct {
+	AWSRegion      string
+	DBTableName    string
+	JWTSecret      string
+	AllowedOrigins []string
+	// Sign in is disabled unless the identity provider is configured
+	OIDCIssuer   string
+	OIDCClientID string
+	OIDCJWKSURL  string
+}
+
+// NewConfig initialises a new config
+func NewConfig() (*Config, error) {
+	awsRegion, err := getEnv("AWS_REGION")
+	if err != nil {
+		return nil, err
+	}
+
+	dbTableName, err := getEnv("DB_TABLE_NAME")
+	if err != nil {
+		return nil, err
+	}
+
+	jwtSecret, err := getEnv("JWT_SECRET")
+	if err != nil {
+		return nil, err
+	}
+
+	allowedOrigins, err := getEnv("ALLOWED_ORIGINS")
+	if err != nil {
+		return nil, err
+	}
+
+	return &Config{
+		AWSRegion:      awsRegion,
+		DBTableName:    dbTableName,
+		JWTSecret:      jwtSecret,
+		AllowedOrigins: strings.Split(allowedOrigins, ","),
+		OIDCIssuer:     os.Getenv("OIDC_ISSUER"),
+		OIDCClientID:   os.Getenv("OIDC_CLIENT_ID"),
+		OIDCJWKSURL:    os.Getenv("OIDC_JWKS_URL"),
+	}, nil
+}
+
+func getEnv(key string) (string, error) {
+	v := os.Getenv(key)
+
+	if v == "" {
+		return "", fmt.Errorf("%s environment variable missing", key)
+	}
+
+	return v, nil
+}
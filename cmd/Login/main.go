@@ -0,0 +1,60 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/jponc/estimatex-serverless/api/schema"
+	"github.com/jponc/estimatex-serverless/internal/api"
+	"github.com/jponc/estimatex-serverless/internal/auth"
+	"github.com/jponc/estimatex-serverless/internal/repository/ddbrepository"
+	"github.com/jponc/estimatex-serverless/pkg/dynamodb"
+	"github.com/jponc/estimatex-serverless/pkg/lambdaresponses"
+	"github.com/jponc/estimatex-serverless/pkg/logger"
+	"github.com/jponc/estimatex-serverless/pkg/metrics"
+)
+
+func main() {
+	logger.Init()
+
+	config, err := NewConfig()
+	if err != nil {
+		log.Fatalf("cannot initialise config %v", err)
+	}
+
+	authClient, err := auth.NewClient(config.JWTSecret)
+	if err != nil {
+		log.Fatalf("cannot initialise auth client %v", err)
+	}
+
+	dynamodbClient, err := dynamodb.NewClient(config.AWSRegion, config.DBTableName)
+	if err != nil {
+		log.Fatalf("cannot initialise dynamodb client %v", err)
+	}
+
+	ddbrepository, err := ddbrepository.NewClient(dynamodbClient)
+	if err != nil {
+		log.Fatalf("cannot initialise ddbrepository %v", err)
+	}
+
+	corsPolicy := lambdaresponses.NewCORSPolicy(config.AllowedOrigins)
+
+	metricsRecorder := metrics.NewRecorder(schema.MetricsNamespace, nil)
+
+	var identities *auth.OIDCVerifier
+	if config.OIDCIssuer != "" && config.OIDCClientID != "" && config.OIDCJWKSURL != "" {
+		identities = auth.NewOIDCVerifier(config.OIDCIssuer, config.OIDCClientID, config.OIDCJWKSURL, &http.Client{Timeout: 5 * time.Second})
+	}
+
+	service := api.NewService(api.Options{
+		Repository: ddbrepository,
+		AuthClient: authClient,
+		CORSPolicy: corsPolicy,
+		Metrics:    metricsRecorder,
+		Identities: identities,
+	})
+	lambda.Start(service.Login)
+}
@@ -7,6 +7,8 @@ import (
 
 // Config
 type Config struct {
+	AWSRegion     string
+	DBTableName   string
 	PusherAppID   string
 	PusherKey     string
 	PusherSecret  string
@@ -15,6 +17,16 @@ type Config struct {
 
 // NewConfig initialises a new config
 func NewConfig() (*Config, error) {
+	awsRegion, err := getEnv("AWS_REGION")
+	if err != nil {
+		return nil, err
+	}
+
+	dbTableName, err := getEnv("DB_TABLE_NAME")
+	if err != nil {
+		return nil, err
+	}
+
 	appID, err := getEnv("PUSHER_APP_ID")
 	if err != nil {
 		return nil, err
@@ -36,6 +48,8 @@ func NewConfig() (*Config, error) {
 	}
 
 	return &Config{
+		AWSRegion:     awsRegion,
+		DBTableName:   dbTableName,
 		PusherAppID:   appID,
 		PusherKey:     key,
 		PusherSecret:  secret,
@@ -0,0 +1,27 @@
+package main
+
+import (
+	log "github.com/sirupsen/logrus"
+
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/jponc/estimatex-serverless/internal/outbox"
+	"github.com/jponc/estimatex-serverless/pkg/logger"
+	"github.com/jponc/estimatex-serverless/pkg/sns"
+)
+
+func main() {
+	logger.Init()
+
+	config, err := NewConfig()
+	if err != nil {
+		log.Fatalf("cannot initialise config %v", err)
+	}
+
+	snsClient, err := sns.NewClient(config.AWSRegion, config.SNSPrefix)
+	if err != nil {
+		log.Fatalf("cannot initialise sns client %v", err)
+	}
+
+	service := outbox.NewService(snsClient)
+	lambda.Start(service.Relay)
+}
@@ -3,13 +3,14 @@ package main
 import (
 	"fmt"
 	"os"
+	"strings"
 )
 
 // Config
 type Config struct {
-	AWSRegion   string
-	DBTableName string
-	SNSPrefix   string
+	AWSRegion      string
+	DBTableName    string
+	AllowedOrigins []string
 }
 
 // NewConfig initialises a new config
@@ -24,15 +25,15 @@ func NewConfig() (*Config, error) {
 		return nil, err
 	}
 
-	snsPrefix, err := getEnv("SNS_PREFIX")
+	allowedOrigins, err := getEnv("ALLOWED_ORIGINS")
 	if err != nil {
 		return nil, err
 	}
 
 	return &Config{
-		AWSRegion:   awsRegion,
-		DBTableName: dbTableName,
-		SNSPrefix:   snsPrefix,
+		AWSRegion:      awsRegion,
+		DBTableName:    dbTableName,
+		AllowedOrigins: strings.Split(allowedOrigins, ","),
 	}, nil
 }
 
@@ -4,23 +4,23 @@ import (
 	log "github.com/sirupsen/logrus"
 
 	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/jponc/estimatex-serverless/api/schema"
 	"github.com/jponc/estimatex-serverless/internal/api"
 	"github.com/jponc/estimatex-serverless/internal/repository/ddbrepository"
 	"github.com/jponc/estimatex-serverless/pkg/dynamodb"
-	"github.com/jponc/estimatex-serverless/pkg/sns"
+	"github.com/jponc/estimatex-serverless/pkg/lambdaresponses"
+	"github.com/jponc/estimatex-serverless/pkg/logger"
+	"github.com/jponc/estimatex-serverless/pkg/metrics"
 )
 
 func main() {
+	logger.Init()
+
 	config, err := NewConfig()
 	if err != nil {
 		log.Fatalf("cannot initialise config %v", err)
 	}
 
-	snsClient, err := sns.NewClient(config.AWSRegion, config.SNSPrefix)
-	if err != nil {
-		log.Fatalf("cannot initialise sns client %v", err)
-	}
-
 	dynamodbClient, err := dynamodb.NewClient(config.AWSRegion, config.DBTableName)
 	if err != nil {
 		log.Fatalf("cannot initialise dynamodb client %v", err)
@@ -31,6 +31,14 @@ func main() {
 		log.Fatalf("cannot initialise ddbrepository %v", err)
 	}
 
-	service := api.NewService(ddbrepository, snsClient, nil)
+	corsPolicy := lambdaresponses.NewCORSPolicy(config.AllowedOrigins)
+
+	metricsRecorder := metrics.NewRecorder(schema.MetricsNamespace, nil)
+
+	service := api.NewService(api.Options{
+		Repository: ddbrepository,
+		CORSPolicy: corsPolicy,
+		Metrics:    metricsRecorder,
+	})
 	lambda.Start(service.ResetVotes)
 }
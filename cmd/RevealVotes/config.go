@@ -3,12 +3,14 @@ package main
 import (
 	"fmt"
 	"os"
+	"strings"
 )
 
 // Config
 type Config struct {
-	AWSRegion string
-	SNSPrefix string
+	AWSRegion      string
+	DBTableName    string
+	AllowedOrigins []string
 }
 
 // NewConfig initialises a new config
@@ -18,14 +20,20 @@ func NewConfig() (*Config, error) {
 		return nil, err
 	}
 
-	snsPrefix, err := getEnv("SNS_PREFIX")
+	dbTableName, err := getEnv("DB_TABLE_NAME")
+	if err != nil {
+		return nil, err
+	}
+
+	allowedOrigins, err := getEnv("ALLOWED_ORIGINS")
 	if err != nil {
 		return nil, err
 	}
 
 	return &Config{
-		AWSRegion: awsRegion,
-		SNSPrefix: snsPrefix,
+		AWSRegion:      awsRegion,
+		DBTableName:    dbTableName,
+		AllowedOrigins: strings.Split(allowedOrigins, ","),
 	}, nil
 }
 
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Config
+type Config struct {
+	AllowedOrigins []string
+}
+
+// NewConfig initialises a new config
+func NewConfig() (*Config, error) {
+	allowedOrigins, err := getEnv("ALLOWED_ORIGINS")
+	if err != nil {
+		return nil, err
+	}
+
+	return &Config{
+		AllowedOrigins: strings.Split(allowedOrigins, ","),
+	}, nil
+}
+
+func getEnv(key string) (string, error) {
+	v := os.Getenv(key)
+
+	if v == "" {
+		return "", fmt.Errorf("%s environment variable missing", key)
+	}
+
+	return v, nil
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	log "github.com/sirupsen/logrus"
+
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/jponc/estimatex-serverless/internal/delivery"
+	"github.com/jponc/estimatex-serverless/internal/integrations"
+	"github.com/jponc/estimatex-serverless/internal/repository/ddbrepository"
+	"github.com/jponc/estimatex-serverless/pkg/dynamodb"
+	"github.com/jponc/estimatex-serverless/pkg/logger"
+	"github.com/jponc/estimatex-serverless/pkg/ssm"
+)
+
+func main() {
+	logger.Init()
+
+	config, err := NewConfig()
+	if err != nil {
+		log.Fatalf("cannot initialise config %v", err)
+	}
+
+	dynamodbClient, err := dynamodb.NewClient(config.AWSRegion, config.DBTableName)
+	if err != nil {
+		log.Fatalf("cannot initialise dynamodb client %v", err)
+	}
+
+	ddbrepository, err := ddbrepository.NewClient(dynamodbClient)
+	if err != nil {
+		log.Fatalf("cannot initialise ddbrepository %v", err)
+	}
+
+	ssmClient, err := ssm.NewClient(config.AWSRegion)
+	if err != nil {
+		log.Fatalf("cannot initialise ssm client %v", err)
+	}
+
+	connector := integrations.NewConnector(ddbrepository, ssmClient, config.IntegrationsParameterPrefix, delivery.NewHTTPClient())
+
+	service := integrations.NewService(ddbrepository, connector)
+	lambda.Start(service.WriteBackEstimate)
+}
@@ -0,0 +1,92 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/jponc/estimatex-serverless/api/schema"
+	"github.com/jponc/estimatex-serverless/internal/repository/ddbrepository"
+	"github.com/jponc/estimatex-serverless/internal/stats"
+	"github.com/jponc/estimatex-serverless/internal/types"
+)
+
+const (
+	defaultAnalyticsSessions = 10
+	// maxAnalyticsSessions bounds a page, every session is a query for its rounds
+	maxAnalyticsSessions = 25
+)
+
+// Analytics reports how accurate the estimates of the caller's room were. For team sessions it
+// covers a page of the team's sessions, newest first, taking `limit` sessions from before the
+// `cursor` a previous page returned.
+func (s *Service) Analytics(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	return s.endpoint(nil, func(ctx context.Context, claims *Claims) (interface{}, error) {
+		return s.analytics(ctx, claims, request.QueryStringParameters["cursor"], request.QueryStringParameters["limit"])
+	}, requireClients(s.ddbrepository != nil), s.withClaims, requireAdmin)(ctx, request)
+}
+
+func (s *Service) analytics(ctx context.Context, claims *Claims, cursorParam, limitParam string) (*schema.AnalyticsResponse, error) {
+	var before time.Time
+	if cursorParam != "" {
+		cursor, err := strconv.ParseInt(cursorParam, 10, 64)
+		if err != nil || cursor <= 0 {
+			return nil, errBadRequest("cursor must be a cursor returned by a previous page")
+		}
+		before = time.Unix(0, cursor)
+	}
+
+	limit := defaultAnalyticsSessions
+	if limitParam != "" {
+		var err error
+		limit, err = strconv.Atoi(limitParam)
+		if err != nil || limit < 1 || limit > maxAnalyticsSessions {
+			return nil, errBadRequest(fmt.Sprintf("limit must be between 1 and %d", maxAnalyticsSessions))
+		}
+	}
+
+	room, err := s.ddbrepository.FindRoom(ctx, claims.RoomID)
+	if err != nil {
+		if errors.Is(err, ddbrepository.ErrNotFound) {
+			return nil, errRoomNotFound
+		}
+
+		return nil, fmt.Errorf("failed to get room: %w", err)
+	}
+
+	// One-off rooms only have their own history
+	rooms := []types.Room{*room}
+	if room.TeamSlug != "" {
+		rooms, err = s.ddbrepository.FindTeamSessions(ctx, room.TeamSlug, before, limit)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get team sessions: %w", err)
+		}
+	} else if !before.IsZero() {
+		rooms = []types.Room{}
+	}
+
+	sessions := []stats.Session{}
+	for _, r := range rooms {
+		rounds, err := s.ddbrepository.FindRounds(ctx, r.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get rounds of %s: %w", r.ID, err)
+		}
+
+		sessions = append(sessions, stats.Session{Room: r, Rounds: rounds})
+	}
+
+	res := &schema.AnalyticsResponse{
+		Analytics: stats.ComputeAnalytics(sessions),
+		TeamSlug:  room.TeamSlug,
+	}
+
+	// A full page may have older sessions after it
+	if room.TeamSlug != "" && len(rooms) == limit {
+		res.NextCursor = strconv.FormatInt(rooms[len(rooms)-1].CreatedAt.UnixNano(), 10)
+	}
+
+	return res, nil
+}
@@ -0,0 +1,207 @@
+package api
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/jponc/estimatex-serverless/api/schema"
+	"github.com/jponc/estimatex-serverless/internal/backlog"
+	"github.com/jponc/estimatex-serverless/internal/repository/ddbrepository"
+	"github.com/jponc/estimatex-serverless/internal/types"
+	"github.com/jponc/estimatex-serverless/pkg/lambdamiddleware"
+	"github.com/jponc/estimatex-serverless/pkg/validator"
+)
+
+// maxBacklogRows limits a single backlog import
+const maxBacklogRows = 500
+
+// backlogColumns maps AddStoryRequest fields to the backlog columns they're read from
+var backlogColumns = map[string]string{
+	"title":        backlog.ColumnTitle,
+	"description":  backlog.ColumnDescription,
+	"external_key": backlog.ColumnKey,
+	"url":          backlog.ColumnLink,
+}
+
+// ImportBacklog adds a CSV or JSON backlog to the end of the queue. Every row is validated before
+// anything is written, the stories are then written in batches and rows that still couldn't be
+// written are reported rather than failing the import. The written stories are queued at once
+// with a single event.
+func (s *Service) ImportBacklog(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	return s.endpoint(nil, func(ctx context.Context, claims *Claims) (interface{}, error) {
+		rows, err := parseBacklog(request)
+		if err != nil {
+			return nil, err
+		}
+
+		return s.importBacklog(ctx, claims, rows)
+	}, requireClients(s.ddbrepository != nil), s.withClaims, requireAdmin)(ctx, request)
+}
+
+func (s *Service) importBacklog(ctx context.Context, claims *Claims, rows []backlog.Row) (*schema.ImportBacklogResponse, error) {
+	reqs, err := s.validateBacklog(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	stories := []types.Story{}
+	for _, r := range reqs {
+		story, err := ddbrepository.NewStory(claims.RoomID, r.Title, r.Description, r.ExternalKey, r.URL)
+		if err != nil {
+			return nil, err
+		}
+
+		stories = append(stories, *story)
+	}
+
+	failedIDs, err := s.ddbrepository.PutStories(ctx, claims.RoomID, stories)
+	if err != nil {
+		return nil, fmt.Errorf("failed to put stories: %w", err)
+	}
+
+	failed := map[string]bool{}
+	for _, id := range failedIDs {
+		failed[id] = true
+	}
+
+	res := &schema.ImportBacklogResponse{
+		Stories: []types.Story{},
+		Failed:  []schema.ImportRowError{},
+	}
+
+	ids := []string{}
+	for i, story := range stories {
+		if failed[story.ID] {
+			res.Failed = append(res.Failed, schema.ImportRowError{
+				Row:     rows[i].Number,
+				Message: "failed to store story, try importing the row again",
+			})
+			continue
+		}
+
+		res.Stories = append(res.Stories, story)
+		ids = append(ids, story.ID)
+	}
+
+	if len(ids) == 0 {
+		return res, nil
+	}
+
+	err = s.queueStories(ctx, claims.RoomID, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// maxQueueAttempts is how many times queueStories reads the room again when the queue changed
+// before it could append to it
+const maxQueueAttempts = 3
+
+// queueStories appends the stored stories to the end of the room's queue. Storing a backlog takes
+// a while, so the queue is read afterwards and read again when it changed in the meantime.
+func (s *Service) queueStories(ctx context.Context, roomID string, ids []string) error {
+	var err error
+
+	for attempt := 0; attempt < maxQueueAttempts; attempt++ {
+		var room *types.Room
+		room, err = s.ddbrepository.FindRoom(ctx, roomID)
+		if err != nil {
+			return fmt.Errorf("failed to get room: %w", err)
+		}
+
+		queue := append(append([]string{}, room.StoryQueue...), ids...)
+
+		var event *schema.Event
+		event, err = s.newEvent(ctx, schema.StoryQueueChanged, roomID, schema.StoryQueueChangedMessage{StoryIDs: queue})
+		if err != nil {
+			return fmt.Errorf("error creating story queue changed event: %w", err)
+		}
+
+		err = s.ddbrepository.UpdateStoryQueue(ctx, roomID, queue, room.Sequence, event)
+		if !errors.Is(err, ddbrepository.ErrConflict) {
+			break
+		}
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to queue stories: %w", err)
+	}
+
+	return nil
+}
+
+// parseBacklog reads the body as CSV when the Content-Type says so and as JSON otherwise
+func parseBacklog(request events.APIGatewayProxyRequest) ([]backlog.Row, error) {
+	var body io.Reader = strings.NewReader(request.Body)
+	if request.IsBase64Encoded {
+		body = base64.NewDecoder(base64.StdEncoding, body)
+	}
+
+	var rows []backlog.Row
+	var err error
+
+	if strings.Contains(lambdamiddleware.Header(request, "Content-Type"), "text/csv") {
+		rows, err = backlog.ParseCSV(body, maxBacklogRows)
+	} else {
+		rows, err = backlog.ParseJSON(body, maxBacklogRows)
+	}
+
+	if errors.Is(err, backlog.ErrTooManyRows) {
+		return nil, errBadRequest(fmt.Sprintf("a backlog can have at most %d rows", maxBacklogRows))
+	}
+	if err != nil {
+		e := *errInvalidBody
+		e.Message = err.Error()
+		return nil, &e
+	}
+
+	if len(rows) == 0 {
+		return nil, errBadRequest("backlog has no rows")
+	}
+
+	return rows, nil
+}
+
+// validateBacklog validates every row like AddStoryRequest, reporting fields as rows[n].column
+func (s *Service) validateBacklog(rows []backlog.Row) ([]schema.AddStoryRequest, error) {
+	reqs := []schema.AddStoryRequest{}
+	verrs := validator.Errors{}
+
+	for _, row := range rows {
+		req := schema.AddStoryRequest{
+			Title:       row.Title,
+			Description: row.Description,
+			ExternalKey: row.Key,
+			URL:         row.Link,
+		}
+
+		err := s.validator.Validate(&req)
+
+		var rowErrs validator.Errors
+		if errors.As(err, &rowErrs) {
+			for _, f := range rowErrs {
+				column := backlogColumns[f.Field]
+				f.Message = fmt.Sprintf("row %d: %s", row.Number, strings.Replace(f.Message, f.Field, column, 1))
+				f.Field = fmt.Sprintf("rows[%d].%s", row.Number, column)
+				verrs = append(verrs, f)
+			}
+		} else if err != nil {
+			return nil, err
+		}
+
+		reqs = append(reqs, req)
+	}
+
+	if len(verrs) > 0 {
+		return nil, errValidation(verrs)
+	}
+
+	return reqs, nil
+}
@@ -0,0 +1,58 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/jponc/estimatex-serverless/api/schema"
+	"github.com/jponc/estimatex-serverless/internal/repository/ddbrepository"
+	"github.com/jponc/estimatex-serverless/internal/types"
+)
+
+// ConnectChat posts the room's reveals and final estimates to a Slack or Teams channel
+func (s *Service) ConnectChat(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	req := &schema.ConnectChatRequest{}
+
+	return s.endpoint(req, func(ctx context.Context, claims *Claims) (interface{}, error) {
+		return s.connectChat(ctx, claims, req)
+	}, requireClients(s.ddbrepository != nil), s.withClaims, requireAdmin)(ctx, request)
+}
+
+func (s *Service) connectChat(ctx context.Context, claims *Claims, req *schema.ConnectChatRequest) (*schema.ConnectChatResponse, error) {
+	integration := ddbrepository.StoredChatIntegration{
+		ChatIntegration: types.ChatIntegration{
+			Kind:      req.Kind,
+			UpdatedAt: time.Now(),
+		},
+		WebhookURL: req.WebhookURL,
+	}
+
+	err := s.ddbrepository.SaveChatIntegration(ctx, claims.RoomID, integration)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save chat integration: %w", err)
+	}
+
+	return &schema.ConnectChatResponse{Integration: integration.ChatIntegration}, nil
+}
+
+func (s *Service) DisconnectChat(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	return s.endpoint(nil, func(ctx context.Context, claims *Claims) (interface{}, error) {
+		return s.disconnectChat(ctx, claims)
+	}, requireClients(s.ddbrepository != nil), s.withClaims, requireAdmin)(ctx, request)
+}
+
+func (s *Service) disconnectChat(ctx context.Context, claims *Claims) (*schema.DisconnectChatResponse, error) {
+	err := s.ddbrepository.RemoveChatIntegration(ctx, claims.RoomID)
+	if err != nil {
+		if errors.Is(err, ddbrepository.ErrNotFound) {
+			return nil, errChatNotConnected
+		}
+
+		return nil, fmt.Errorf("failed to remove chat integration: %w", err)
+	}
+
+	return &schema.DisconnectChatResponse{}, nil
+}
@@ -0,0 +1,86 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/jponc/estimatex-serverless/api/schema"
+	"github.com/jponc/estimatex-serverless/internal/repository/ddbrepository"
+	"github.com/jponc/estimatex-serverless/pkg/lambdaresponses"
+	"github.com/jponc/estimatex-serverless/pkg/logger"
+	"github.com/jponc/estimatex-serverless/pkg/validator"
+)
+
+var (
+	errInvalidBody         = lambdaresponses.NewAPIError(http.StatusBadRequest, schema.ErrCodeInvalidBody, "failed to unmarshal body")
+	errNotAllowed          = lambdaresponses.NewAPIError(http.StatusForbidden, schema.ErrCodeNotAllowed, "not allowed")
+	errRoomNotFound        = lambdaresponses.NewAPIError(http.StatusNotFound, schema.ErrCodeRoomNotFound, "room not found")
+	errParticipantNotFound = lambdaresponses.NewAPIError(http.StatusNotFound, schema.ErrCodeParticipantNotFound, "participant not found")
+	errStoryNotFound       = lambdaresponses.NewAPIError(http.StatusNotFound, schema.ErrCodeStoryNotFound, "story not found")
+	errWebhookNotFound     = lambdaresponses.NewAPIError(http.StatusNotFound, schema.ErrCodeWebhookNotFound, "webhook not found")
+	errTeamNotFound        = lambdaresponses.NewAPIError(http.StatusNotFound, schema.ErrCodeTeamNotFound, "team not found")
+	errParticipantGone     = lambdaresponses.NewAPIError(http.StatusGone, schema.ErrCodeGone, "participant already left or was renamed")
+	errParticipantExists   = lambdaresponses.NewAPIError(http.StatusConflict, schema.ErrCodeParticipantExists, "participant already exists")
+	errTeamExists          = lambdaresponses.NewAPIError(http.StatusConflict, schema.ErrCodeTeamExists, "team slug is taken")
+	errRoomFull            = lambdaresponses.NewAPIError(http.StatusConflict, schema.ErrCodeRoomFull, fmt.Sprintf("room is full, at most %d participants can join", ddbrepository.MaxRoomParticipants))
+	errRoundFinalized      = lambdaresponses.NewAPIError(http.StatusConflict, schema.ErrCodeRoundFinalized, "round already has a final estimate, start a re-vote instead")
+	errTrackerNotConnected = lambdaresponses.NewAPIError(http.StatusBadRequest, schema.ErrCodeTrackerNotConnected, "room has no tracker connected")
+	errChatNotConnected    = lambdaresponses.NewAPIError(http.StatusNotFound, schema.ErrCodeChatNotConnected, "room has no chat connected")
+	errTokenRevoked        = lambdaresponses.NewAPIError(http.StatusUnauthorized, schema.ErrCodeUnauthorized, "participant is no longer in the room")
+	errInvalidUserToken    = lambdaresponses.NewAPIError(http.StatusUnauthorized, schema.ErrCodeUnauthorized, "invalid or expired user token")
+	errInvalidIDToken      = lambdaresponses.NewAPIError(http.StatusUnauthorized, schema.ErrCodeUnauthorized, "invalid ID token")
+	errLoginDisabled       = lambdaresponses.NewAPIError(http.StatusNotFound, schema.ErrCodeNotFound, "sign in isn't enabled")
+)
+
+// errModifiedConcurrently is sent with the 409 of writes that lost a race, reading again and
+// retrying them is safe
+var errModifiedConcurrently = errors.New("modified concurrently, try again")
+
+// errBadRequest is a 400 for request problems not tied to a single field
+func errBadRequest(message string) *lambdaresponses.APIError {
+	return lambdaresponses.NewAPIError(http.StatusBadRequest, schema.ErrCodeBadRequest, message)
+}
+
+// errValidation is a 422 listing every invalid field
+func errValidation(verrs validator.Errors) *lambdaresponses.APIError {
+	e := lambdaresponses.NewAPIError(http.StatusUnprocessableEntity, schema.ErrCodeValidationFailed, verrs.Error())
+
+	for _, f := range verrs {
+		e.Details = append(e.Details, lambdaresponses.FieldError{
+			Field:   f.Field,
+			Code:    f.Rule,
+			Message: f.Message,
+		})
+	}
+
+	return e
+}
+
+// respondError responds with err tagged with the request ID. API errors are sent as they are,
+// repository errors are mapped to their status and anything else is logged and hidden behind a 500.
+func respondError(ctx context.Context, request events.APIGatewayProxyRequest, err error) (events.APIGatewayProxyResponse, error) {
+	requestID := request.RequestContext.RequestID
+
+	var apiErr *lambdaresponses.APIError
+	switch {
+	case errors.As(err, &apiErr):
+		e := *apiErr
+		e.RequestID = requestID
+		return lambdaresponses.RespondError(&e)
+	case errors.Is(err, ddbrepository.ErrNotFound):
+		return lambdaresponses.Respond404(requestID, ddbrepository.ErrNotFound)
+	case errors.Is(err, ddbrepository.ErrAlreadyExists):
+		return lambdaresponses.Respond409(requestID, ddbrepository.ErrAlreadyExists)
+	case errors.Is(err, ddbrepository.ErrConflict):
+		return lambdaresponses.Respond409(requestID, errModifiedConcurrently)
+	}
+
+	logger.FromContext(ctx).Errorf("%v", err)
+
+	e := lambdaresponses.NewAPIError(http.StatusInternalServerError, schema.ErrCodeInternal, "Internal Server Error")
+	e.RequestID = requestID
+	return lambdaresponses.RespondError(e)
+}
@@ -0,0 +1,48 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/jponc/estimatex-serverless/api/schema"
+)
+
+// roomEventsPageSize bounds the events of a response, a long lived room's log doesn't fit one
+const roomEventsPageSize = 100
+
+// GetRoomEvents returns a page of the room events after the `since` sequence number so
+// reconnecting clients can catch up on what they missed
+func (s *Service) GetRoomEvents(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	return s.endpoint(nil, func(ctx context.Context, claims *Claims) (interface{}, error) {
+		return s.getRoomEvents(ctx, claims, request.QueryStringParameters["since"])
+	}, requireClients(s.ddbrepository != nil), s.withClaims)(ctx, request)
+}
+
+func (s *Service) getRoomEvents(ctx context.Context, claims *Claims, sinceParam string) (*schema.GetRoomEventsResponse, error) {
+	var since int64
+	if sinceParam != "" {
+		var err error
+		since, err = strconv.ParseInt(sinceParam, 10, 64)
+		if err != nil || since < 0 {
+			return nil, errBadRequest("since must be a non-negative integer")
+		}
+	}
+
+	events, more, err := s.ddbrepository.FindRoomEvents(ctx, claims.RoomID, since, roomEventsPageSize)
+	if err != nil {
+		return nil, fmt.Errorf("error finding room events: %w", err)
+	}
+
+	res := &schema.GetRoomEventsResponse{
+		Events:  events,
+		HasMore: more,
+	}
+
+	if more {
+		res.NextSince = events[len(events)-1].Sequence
+	}
+
+	return res, nil
+}
@@ -0,0 +1,75 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/jponc/estimatex-serverless/internal/export"
+	"github.com/jponc/estimatex-serverless/pkg/lambdamiddleware"
+)
+
+// ExportRoom downloads the room's results as CSV or JSON, picked by the format query parameter or
+// else the Accept header
+func (s *Service) ExportRoom(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	return s.endpoint(nil, func(ctx context.Context, claims *Claims) (interface{}, error) {
+		format, err := exportFormat(request)
+		if err != nil {
+			return nil, err
+		}
+
+		return s.exportRoom(ctx, claims, format)
+	}, requireClients(s.ddbrepository != nil), s.withClaims)(ctx, request)
+}
+
+func (s *Service) exportRoom(ctx context.Context, claims *Claims, format string) (*fileResponse, error) {
+	partition, err := s.ddbrepository.FindRoomPartition(ctx, claims.RoomID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get room partition: %w", err)
+	}
+
+	session := export.NewSession(partition, time.Now())
+
+	res := &fileResponse{
+		filename: fmt.Sprintf("estimatex-%s.%s", claims.RoomID, format),
+	}
+
+	buf := &bytes.Buffer{}
+
+	switch format {
+	case export.FormatCSV:
+		res.contentType = "text/csv; charset=utf-8"
+		err = export.WriteCSV(buf, session)
+	default:
+		res.contentType = "application/json"
+		err = export.WriteJSON(buf, session)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to write export: %w", err)
+	}
+
+	res.body = buf.Bytes()
+
+	return res, nil
+}
+
+// exportFormat reads the format query parameter, falling back to the Accept header and then JSON
+func exportFormat(request events.APIGatewayProxyRequest) (string, error) {
+	switch format := strings.ToLower(request.QueryStringParameters["format"]); format {
+	case export.FormatCSV, export.FormatJSON:
+		return format, nil
+	case "":
+	default:
+		return "", errBadRequest("format must be csv or json")
+	}
+
+	if strings.Contains(lambdamiddleware.Header(request, "Accept"), "text/csv") {
+		return export.FormatCSV, nil
+	}
+
+	return export.FormatJSON, nil
+}
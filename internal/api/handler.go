@@ -0,0 +1,175 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/jponc/estimatex-serverless/internal/repository/ddbrepository"
+	"github.com/jponc/estimatex-serverless/pkg/lambdamiddleware"
+	"github.com/jponc/estimatex-serverless/pkg/lambdaresponses"
+	"github.com/jponc/estimatex-serverless/pkg/logger"
+	log "github.com/sirupsen/logrus"
+)
+
+// Claims are the participant claims the Authoriser passes through the request context
+type Claims struct {
+	RoomID  string
+	Name    string
+	IsAdmin bool
+	// UserID is set when the participant joined signed in
+	UserID string
+}
+
+type claimsKey struct{}
+
+// claimsFromContext returns the claims withClaims stored in ctx
+func claimsFromContext(ctx context.Context) (*Claims, bool) {
+	claims, ok := ctx.Value(claimsKey{}).(*Claims)
+	return claims, ok
+}
+
+// endpointFunc is the handler at the end of the chain, the response is sent as a 200 and the error
+// is mapped by respondError
+type endpointFunc func(ctx context.Context, claims *Claims) (interface{}, error)
+
+// fileResponse is returned by an endpointFunc to send a download instead of a JSON body
+type fileResponse struct {
+	contentType string
+	filename    string
+	body        []byte
+}
+
+// endpoint chains fn behind CORS, panic recovery, request logging and the given middlewares.
+// When req isn't nil the body is decoded and validated into it before fn runs.
+func (s *Service) endpoint(req interface{}, fn endpointFunc, middlewares ...lambdamiddleware.Middleware) lambdamiddleware.Handler {
+	h := func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		claims, _ := claimsFromContext(ctx)
+
+		res, err := fn(ctx, claims)
+		if err != nil {
+			return respondError(ctx, request, err)
+		}
+
+		if f, ok := res.(*fileResponse); ok {
+			return lambdaresponses.RespondFile(f.contentType, f.filename, f.body)
+		}
+
+		return lambdaresponses.Respond200(res)
+	}
+
+	chain := []lambdamiddleware.Middleware{
+		lambdamiddleware.CORS(s.corsPolicy),
+		lambdamiddleware.Recover,
+		lambdamiddleware.Logging,
+	}
+	chain = append(chain, middlewares...)
+	if req != nil {
+		chain = append(chain, s.decodeBody(req))
+	}
+
+	return lambdamiddleware.Chain(h, chain...)
+}
+
+// requireClients fails the request when the Lambda wasn't given the clients the handler needs
+func requireClients(ok bool) lambdamiddleware.Middleware {
+	return func(next lambdamiddleware.Handler) lambdamiddleware.Handler {
+		return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+			if !ok {
+				logger.FromContext(ctx).Errorf("clients required by %s are nil", request.Path)
+				return lambdaresponses.Respond500()
+			}
+
+			return next(ctx, request)
+		}
+	}
+}
+
+// withClaims extracts the Authoriser context into Claims stored on ctx. Tokens outlive the
+// participant, so the request is only let through while the participant is still in the room
+// under the token's name and joined before the token was issued.
+func (s *Service) withClaims(next lambdamiddleware.Handler) lambdamiddleware.Handler {
+	return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		roomID, ok := request.RequestContext.Authorizer["RoomID"].(string)
+		if !ok || roomID == "" {
+			return respondError(ctx, request, fmt.Errorf("no room id in authorizer context"))
+		}
+
+		name, ok := request.RequestContext.Authorizer["Name"].(string)
+		if !ok || name == "" {
+			return respondError(ctx, request, fmt.Errorf("no name in authorizer context"))
+		}
+
+		// API Gateway passes every authorizer context value through as a string
+		isAdmin, ok := request.RequestContext.Authorizer["IsAdmin"].(string)
+		if !ok {
+			return respondError(ctx, request, fmt.Errorf("no is admin in authorizer context"))
+		}
+
+		// Tokens issued before sign in was supported have no user ID
+		userID, _ := request.RequestContext.Authorizer["UserID"].(string)
+
+		// Nor did they say when they were issued
+		issuedAt, _ := request.RequestContext.Authorizer["IssuedAt"].(string)
+
+		claims := &Claims{
+			RoomID:  roomID,
+			Name:    name,
+			IsAdmin: isAdmin == "true",
+			UserID:  userID,
+		}
+
+		ctx = context.WithValue(ctx, claimsKey{}, claims)
+		ctx = logger.WithFields(ctx, log.Fields{
+			logger.FieldRoomID:      claims.RoomID,
+			logger.FieldParticipant: claims.Name,
+		})
+
+		participant, err := s.ddbrepository.FindParticipant(ctx, roomID, name)
+		if err != nil {
+			if errors.Is(err, ddbrepository.ErrNotFound) {
+				return respondError(ctx, request, errTokenRevoked)
+			}
+
+			return respondError(ctx, request, fmt.Errorf("failed to get participant: %w", err))
+		}
+
+		if iat, err := strconv.ParseInt(issuedAt, 10, 64); err == nil && iat < participant.CreatedAt.Unix() {
+			return respondError(ctx, request, errTokenRevoked)
+		}
+
+		return next(ctx, request)
+	}
+}
+
+// requireAdmin only lets the room admin through, it must run after withClaims
+func requireAdmin(next lambdamiddleware.Handler) lambdamiddleware.Handler {
+	return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		claims, ok := claimsFromContext(ctx)
+		if !ok {
+			return respondError(ctx, request, fmt.Errorf("requireAdmin used without withClaims"))
+		}
+
+		if !claims.IsAdmin {
+			return respondError(ctx, request, errNotAllowed)
+		}
+
+		return next(ctx, request)
+	}
+}
+
+// decodeBody strictly decodes and validates the body into req
+func (s *Service) decodeBody(req interface{}) lambdamiddleware.Middleware {
+	return func(next lambdamiddleware.Handler) lambdamiddleware.Handler {
+		return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+			err := s.decodeRequest(request, req)
+			if err != nil {
+				return respondError(ctx, request, err)
+			}
+
+			return next(ctx, request)
+		}
+	}
+}
@@ -0,0 +1,11 @@
+package api
+
+const (
+	metricRoomsCreated        = "RoomsCreated"
+	metricParticipantsJoined  = "ParticipantsJoined"
+	metricVotesCast           = "VotesCast"
+	metricReveals             = "Reveals"
+	metricVotesPerRound       = "VotesPerRound"
+	metricParticipantsPerRoom = "ParticipantsPerRoom"
+	metricTimeToReveal        = "TimeToReveal"
+)
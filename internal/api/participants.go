@@ -2,28 +2,117 @@ package api
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
 	"github.com/aws/aws-lambda-go/events"
-	"github.com/jponc/estimatex-serverless/pkg/lambdaresponses"
-	log "github.com/sirupsen/logrus"
+	"github.com/jponc/estimatex-serverless/api/schema"
+	"github.com/jponc/estimatex-serverless/internal/repository/ddbrepository"
+	"github.com/jponc/estimatex-serverless/internal/types"
 )
 
 func (s *Service) FindParticipants(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
-	if s.ddbrepository == nil {
-		log.Errorf("ddbrepository is nil")
-		return lambdaresponses.Respond500()
+	return s.endpoint(nil, func(ctx context.Context, claims *Claims) (interface{}, error) {
+		return s.findParticipants(ctx, claims)
+	}, requireClients(s.ddbrepository != nil), s.withClaims)(ctx, request)
+}
+
+func (s *Service) findParticipants(ctx context.Context, claims *Claims) (*[]types.Participant, error) {
+	participants, err := s.ddbrepository.FindParticipants(ctx, claims.RoomID)
+	if err != nil {
+		return nil, fmt.Errorf("error finding participants: %w", err)
+	}
+
+	// Comments are shared with the reveal
+	for i := range *participants {
+		(*participants)[i].HideComments()
+	}
+
+	return participants, nil
+}
+
+func (s *Service) KickParticipant(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	req := &schema.KickParticipantRequest{}
+
+	return s.endpoint(req, func(ctx context.Context, claims *Claims) (interface{}, error) {
+		return s.kickParticipant(ctx, claims, req)
+	}, requireClients(s.ddbrepository != nil), s.withClaims, requireAdmin)(ctx, request)
+}
+
+func (s *Service) kickParticipant(ctx context.Context, claims *Claims, req *schema.KickParticipantRequest) (*schema.KickParticipantResponse, error) {
+	if req.Name == claims.Name {
+		return nil, errBadRequest("can't kick yourself")
 	}
 
-	roomID, ok := request.RequestContext.Authorizer["RoomID"].(string)
-	if !ok {
-		return lambdaresponses.Respond500()
+	msg := schema.ParticipantKickedMessage{
+		ParticipantName: req.Name,
+	}
+
+	event, err := s.newEvent(ctx, schema.ParticipantKicked, claims.RoomID, msg)
+	if err != nil {
+		return nil, fmt.Errorf("error creating participant kicked event: %w", err)
 	}
 
-	participants, err := s.ddbrepository.FindParticipants(ctx, roomID)
+	err = s.ddbrepository.DeleteParticipant(ctx, claims.RoomID, req.Name, event)
 	if err != nil {
-		log.Errorf("error finding participants: %w", err)
-		return lambdaresponses.Respond500()
+		if errors.Is(err, ddbrepository.ErrNotFound) {
+			return nil, errParticipantNotFound
+		}
+
+		return nil, fmt.Errorf("error kicking participant: %w", err)
+	}
+
+	return &schema.KickParticipantResponse{}, nil
+}
+
+func (s *Service) RenameParticipant(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	req := &schema.RenameParticipantRequest{}
+
+	return s.endpoint(req, func(ctx context.Context, claims *Claims) (interface{}, error) {
+		return s.renameParticipant(ctx, claims, req)
+	}, requireClients(s.ddbrepository != nil && s.authClient != nil), s.withClaims)(ctx, request)
+}
+
+func (s *Service) renameParticipant(ctx context.Context, claims *Claims, req *schema.RenameParticipantRequest) (*schema.RenameParticipantResponse, error) {
+	p, err := s.ddbrepository.FindParticipant(ctx, claims.RoomID, claims.Name)
+	if err != nil {
+		if errors.Is(err, ddbrepository.ErrNotFound) {
+			return nil, errParticipantNotFound
+		}
+
+		return nil, fmt.Errorf("failed to get participant: %w", err)
+	}
+
+	msg := schema.ParticipantRenamedMessage{
+		ParticipantName:    claims.Name,
+		NewParticipantName: req.Name,
+	}
+
+	event, err := s.newEvent(ctx, schema.ParticipantRenamed, claims.RoomID, msg)
+	if err != nil {
+		return nil, fmt.Errorf("error creating participant renamed event: %w", err)
+	}
+
+	renamed, err := s.ddbrepository.RenameParticipant(ctx, p, req.Name, event)
+	if err != nil {
+		if errors.Is(err, ddbrepository.ErrNotFound) {
+			return nil, errParticipantGone
+		}
+		if errors.Is(err, ddbrepository.ErrAlreadyExists) {
+			return nil, errParticipantExists
+		}
+
+		return nil, fmt.Errorf("error renaming participant: %w", err)
+	}
+
+	token, err := s.authClient.CreateAccessToken(*renamed)
+	if err != nil {
+		return nil, fmt.Errorf("error creating access token: %w", err)
+	}
+
+	res := &schema.RenameParticipantResponse{
+		AccessToken: token,
 	}
 
-	return lambdaresponses.Respond200(participants)
+	return res, nil
 }
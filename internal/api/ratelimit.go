@@ -0,0 +1,81 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/jponc/estimatex-serverless/internal/ratelimit"
+	"github.com/jponc/estimatex-serverless/pkg/lambdamiddleware"
+	"github.com/jponc/estimatex-serverless/pkg/lambdaresponses"
+	"github.com/jponc/estimatex-serverless/pkg/logger"
+)
+
+var (
+	hostRoomLimit   = ratelimit.Rule{Name: "HostRoom", Burst: 5, Every: 2 * time.Minute}
+	createTeamLimit = ratelimit.Rule{Name: "CreateTeam", Burst: 3, Every: 10 * time.Minute}
+	loginLimit      = ratelimit.Rule{Name: "Login", Burst: 10, Every: time.Minute}
+	joinRoomLimit   = ratelimit.Rule{Name: "JoinRoom", Burst: 10, Every: 30 * time.Second}
+	castVoteLimit   = ratelimit.Rule{Name: "CastVote", Burst: 10, Every: 2 * time.Second}
+	// roomVoteLimit bounds the votes of a whole room, anyone with its ID can join under many names
+	roomVoteLimit = ratelimit.Rule{Name: "CastVoteRoom", Burst: 150, Every: 200 * time.Millisecond}
+)
+
+// errTooManyRequests is sent with the 429 of a client that used up a rule
+var errTooManyRequests = errors.New("too many requests")
+
+// rateKeyFunc picks the client a request is counted against, empty skips rate limiting
+type rateKeyFunc func(ctx context.Context, request events.APIGatewayProxyRequest) string
+
+// bySourceIP counts unauthenticated requests against the caller's IP
+func bySourceIP(_ context.Context, request events.APIGatewayProxyRequest) string {
+	return request.RequestContext.Identity.SourceIP
+}
+
+// byParticipant counts authenticated requests against the participant, it must run after withClaims
+func byParticipant(ctx context.Context, _ events.APIGatewayProxyRequest) string {
+	claims, ok := claimsFromContext(ctx)
+	if !ok {
+		return ""
+	}
+
+	return fmt.Sprintf("%s_%s", claims.RoomID, claims.Name)
+}
+
+// byRoom counts authenticated requests against the participant's room, it must run after withClaims
+func byRoom(ctx context.Context, _ events.APIGatewayProxyRequest) string {
+	claims, ok := claimsFromContext(ctx)
+	if !ok {
+		return ""
+	}
+
+	return claims.RoomID
+}
+
+// rateLimit responds with a 429 once the client has used up rule, including when it keeps losing
+// the race for its bucket. Limiter failures let the request through, an outage of the limiter
+// shouldn't take the API down with it.
+func (s *Service) rateLimit(rule ratelimit.Rule, keyFunc rateKeyFunc) lambdamiddleware.Middleware {
+	return func(next lambdamiddleware.Handler) lambdamiddleware.Handler {
+		return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+			key := keyFunc(ctx, request)
+			if s.limiter == nil || key == "" {
+				return next(ctx, request)
+			}
+
+			res, err := s.limiter.Allow(ctx, rule, key)
+			if err != nil {
+				logger.FromContext(ctx).Warnf("rate limiter failed, allowing request: %v", err)
+				return next(ctx, request)
+			}
+
+			if !res.Allowed {
+				return lambdaresponses.Respond429(request.RequestContext.RequestID, errTooManyRequests, res.RetryAfter)
+			}
+
+			return next(ctx, request)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/jponc/estimatex-serverless/pkg/validator"
+)
+
+// maxCardLength limits each card of a custom deck, it's shown on a small card in the UI
+const maxCardLength = 16
+
+// decodeRequest strictly decodes the request body into req, rejecting unknown fields, and
+// validates it against its `validate` tags
+func (s *Service) decodeRequest(request events.APIGatewayProxyRequest, req interface{}) error {
+	d := json.NewDecoder(strings.NewReader(request.Body))
+	d.DisallowUnknownFields()
+
+	err := d.Decode(req)
+	if err != nil {
+		e := *errInvalidBody
+		e.Message = fmt.Sprintf("failed to unmarshal body: %v", err)
+		return &e
+	}
+
+	if d.More() {
+		e := *errInvalidBody
+		e.Message = "failed to unmarshal body: unexpected data after the JSON object"
+		return &e
+	}
+
+	return s.validate(req)
+}
+
+// validate turns field errors into a single 422 listing all of them
+func (s *Service) validate(req interface{}) error {
+	err := s.validator.Validate(req)
+
+	var verrs validator.Errors
+	if errors.As(err, &verrs) {
+		return errValidation(verrs)
+	}
+
+	return err
+}
+
+// validateDeck checks the cards of a custom deck are present, short and unique
+func validateDeck(deck []string) error {
+	verrs := validator.Errors{}
+	seen := map[string]bool{}
+
+	for i, card := range deck {
+		card = strings.TrimSpace(card)
+		deck[i] = card
+		field := fmt.Sprintf("deck[%d]", i)
+
+		switch {
+		case card == "":
+			verrs = append(verrs, validator.FieldError{Field: field, Rule: "required", Message: fmt.Sprintf("%s can't be blank", field)})
+		case utf8.RuneCountInString(card) > maxCardLength:
+			verrs = append(verrs, validator.FieldError{Field: field, Rule: "max", Message: fmt.Sprintf("%s must be at most %d long", field, maxCardLength)})
+		case seen[card]:
+			verrs = append(verrs, validator.FieldError{Field: field, Rule: "unique", Message: fmt.Sprintf("%s is a duplicate card", field)})
+		}
+
+		seen[card] = true
+	}
+
+	if len(verrs) > 0 {
+		return errValidation(verrs)
+	}
+
+	return nil
+}
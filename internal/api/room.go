@@ -2,158 +2,224 @@ package api
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/jponc/estimatex-serverless/api/schema"
 	"github.com/jponc/estimatex-serverless/internal/repository/ddbrepository"
-	"github.com/jponc/estimatex-serverless/pkg/lambdaresponses"
-	log "github.com/sirupsen/logrus"
+	"github.com/jponc/estimatex-serverless/internal/types"
+	"github.com/jponc/estimatex-serverless/pkg/metrics"
 )
 
 func (s *Service) HostRoom(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
-	if s.authClient == nil || s.ddbrepository == nil {
-		log.Errorf("authClient or ddbrepository is nil")
-		return lambdaresponses.Respond500()
-	}
-
 	req := &schema.HostRoomRequest{}
 
-	err := json.Unmarshal([]byte(request.Body), req)
+	return s.endpoint(req, func(ctx context.Context, claims *Claims) (interface{}, error) {
+		return s.hostRoom(ctx, claims, req)
+	}, requireClients(s.ddbrepository != nil && s.authClient != nil && s.roomIDs != nil), s.rateLimit(hostRoomLimit, bySourceIP))(ctx, request)
+}
+
+func (s *Service) hostRoom(ctx context.Context, _ *Claims, req *schema.HostRoomRequest) (*schema.HostRoomResponse, error) {
+	err := validateDeck(req.Deck)
 	if err != nil {
-		return lambdaresponses.Respond400(fmt.Errorf("failed to unmarshal body"))
+		return nil, err
 	}
 
-	if req.Name == "" {
-		return lambdaresponses.Respond400(fmt.Errorf("name can't be blank"))
+	userID, err := s.userID(req.UserToken)
+	if err != nil {
+		return nil, err
 	}
 
-	// TODO Wrap both in a transaction, dynamoDB now supports transactions
-	room, err := s.ddbrepository.CreateRoom(ctx)
+	deck := req.Deck
+
+	if req.TeamSlug != "" {
+		team, err := s.findTeamWithKey(ctx, req.TeamSlug, req.TeamKey)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(deck) == 0 {
+			deck = team.Deck
+		}
+	}
+
+	msg := schema.ParticipantJoinedMessage{
+		ParticipantName: req.Name,
+		IsAdmin:         true,
+		UserID:          userID,
+	}
+
+	// The room ID is only known once the repository has found a free one
+	event, err := s.newEvent(ctx, schema.ParticipantJoined, "", msg)
 	if err != nil {
-		log.Errorf("error creating room: %w", err)
-		return lambdaresponses.Respond500()
+		return nil, fmt.Errorf("error creating participant joined event: %w", err)
 	}
 
-	participant, err := s.ddbrepository.CreateParticipant(ctx, room.ID, req.Name, true)
+	room, participant, err := s.ddbrepository.CreateRoom(ctx, s.roomIDs, deck, req.TeamSlug, req.Name, userID, event)
 	if err != nil {
-		log.Errorf("error creating participant: %w", err)
-		return lambdaresponses.Respond500()
+		if errors.Is(err, ddbrepository.ErrNotFound) {
+			return nil, errTeamNotFound
+		}
+
+		return nil, fmt.Errorf("error creating room: %w", err)
 	}
 
 	token, err := s.authClient.CreateAccessToken(*participant)
 	if err != nil {
-		log.Errorf("error creating access token: %w", err)
-		return lambdaresponses.Respond500()
+		return nil, fmt.Errorf("error creating access token: %w", err)
 	}
 
-	res := schema.HostRoomResponse{
+	s.metrics.Emit(ctx, metrics.Count(metricRoomsCreated, 1), metrics.Count(metricParticipantsJoined, 1))
+
+	res := &schema.HostRoomResponse{
 		RoomID:      room.ID,
 		AccessToken: token,
 	}
 
-	return lambdaresponses.Respond200(res)
+	return res, nil
 }
 
 func (s *Service) FindRoom(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
-	if s.ddbrepository == nil {
-		log.Errorf("ddbrepository is nil")
-		return lambdaresponses.Respond500()
-	}
-
-	roomID, ok := request.RequestContext.Authorizer["RoomID"].(string)
-	if !ok {
-		return lambdaresponses.Respond500()
-	}
-
-	if roomID == "" {
-		return lambdaresponses.Respond400(fmt.Errorf("roomID can't be blank"))
-	}
+	return s.endpoint(nil, func(ctx context.Context, claims *Claims) (interface{}, error) {
+		return s.findRoom(ctx, claims)
+	}, requireClients(s.ddbrepository != nil), s.withClaims)(ctx, request)
+}
 
-	room, err := s.ddbrepository.FindRoom(ctx, roomID)
+func (s *Service) findRoom(ctx context.Context, claims *Claims) (*schema.FindRoomResponse, error) {
+	room, err := s.ddbrepository.FindRoom(ctx, claims.RoomID)
 	if err != nil {
 		if errors.Is(err, ddbrepository.ErrNotFound) {
-			return lambdaresponses.Respond404(fmt.Errorf("room not found"))
-		} else {
-
-			log.Errorf("error finding room: %w", err)
-			return lambdaresponses.Respond500()
+			return nil, errRoomNotFound
 		}
+
+		return nil, fmt.Errorf("error finding room: %w", err)
 	}
 
-	res := schema.FindRoomResponse{
+	// Rooms hosted before decks were configurable vote with the default deck
+	room.Deck = room.Cards()
+
+	res := &schema.FindRoomResponse{
 		Room: *room,
 	}
 
-	return lambdaresponses.Respond200(res)
+	return res, nil
 }
 
 func (s *Service) JoinRoom(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
-	if s.ddbrepository == nil || s.snsClient == nil || s.authClient == nil {
+	req := &schema.JoinRoomRequest{}
 
-		log.Errorf("ddbrepository or snsClient or authClient is nil")
-		return lambdaresponses.Respond500()
-	}
+	return s.endpoint(req, func(ctx context.Context, claims *Claims) (interface{}, error) {
+		return s.joinRoom(ctx, claims, req)
+	}, requireClients(s.ddbrepository != nil && s.authClient != nil), s.rateLimit(joinRoomLimit, bySourceIP))(ctx, request)
+}
 
-	req := &schema.JoinRoomRequest{}
+func (s *Service) joinRoom(ctx context.Context, _ *Claims, req *schema.JoinRoomRequest) (*schema.JoinRoomResponse, error) {
+	userID, err := s.userID(req.UserToken)
+	if err != nil {
+		return nil, err
+	}
 
-	err := json.Unmarshal([]byte(request.Body), req)
+	roomID, isAdmin, err := s.resolveJoin(ctx, req, userID)
 	if err != nil {
-		return lambdaresponses.Respond400(fmt.Errorf("failed to unmarshal body"))
+		return nil, err
 	}
 
-	if req.RoomID == "" {
-		return lambdaresponses.Respond400(fmt.Errorf("roomID can't be blank"))
+	_, err = s.ddbrepository.FindRoom(ctx, roomID)
+	if err != nil {
+		if errors.Is(err, ddbrepository.ErrNotFound) {
+			return nil, errRoomNotFound
+		}
+
+		return nil, fmt.Errorf("error finding room: %w", err)
 	}
 
-	if req.Name == "" {
-		return lambdaresponses.Respond400(fmt.Errorf("name can't be blank"))
+	existingParticipant, err := s.ddbrepository.FindParticipant(ctx, roomID, req.Name)
+	if err != nil && !errors.Is(err, ddbrepository.ErrNotFound) {
+		return nil, fmt.Errorf("error finding participant: %w", err)
+	}
+	if existingParticipant != nil {
+		return nil, errParticipantExists
 	}
 
-	_, err = s.ddbrepository.FindRoom(ctx, req.RoomID)
+	participants, err := s.ddbrepository.FindParticipants(ctx, roomID)
 	if err != nil {
-		return lambdaresponses.Respond404(fmt.Errorf("room ID not found"))
+		return nil, fmt.Errorf("error finding participants: %w", err)
+	}
+	if len(*participants) >= ddbrepository.MaxRoomParticipants {
+		return nil, errRoomFull
 	}
 
-	existingParticipant, err := s.ddbrepository.FindParticipant(ctx, req.RoomID, req.Name)
-	if err != nil && !errors.Is(err, ddbrepository.ErrNotFound) {
-
-		log.Errorf("error finding participant: %w", err)
-		return lambdaresponses.Respond500()
+	msg := schema.ParticipantJoinedMessage{
+		ParticipantName: req.Name,
+		IsAdmin:         isAdmin,
+		UserID:          userID,
 	}
-	if existingParticipant != nil {
-		return lambdaresponses.Respond404(fmt.Errorf("participant already exists"))
+
+	event, err := s.newEvent(ctx, schema.ParticipantJoined, roomID, msg)
+	if err != nil {
+		return nil, fmt.Errorf("error creating participant joined event: %w", err)
 	}
 
-	participant, err := s.ddbrepository.CreateParticipant(ctx, req.RoomID, req.Name, false)
+	participant, err := s.ddbrepository.CreateParticipant(ctx, roomID, req.Name, isAdmin, userID, event)
 	if err != nil {
-		log.Errorf("error creating participant: %w", err)
-		return lambdaresponses.Respond500()
+		if errors.Is(err, ddbrepository.ErrAlreadyExists) {
+			return nil, errParticipantExists
+		}
+
+		return nil, fmt.Errorf("error creating participant: %w", err)
 	}
 
 	token, err := s.authClient.CreateAccessToken(*participant)
 	if err != nil {
+		return nil, fmt.Errorf("error creating access token: %w", err)
+	}
 
-		log.Errorf("error creating access token: %w", err)
-		return lambdaresponses.Respond500()
+	s.metrics.Emit(ctx, metrics.Count(metricParticipantsJoined, 1))
+
+	res := &schema.JoinRoomResponse{
+		RoomID:      roomID,
+		AccessToken: token,
 	}
 
-	msg := schema.ParticipantJoinedMessage{
-		RoomID:          req.RoomID,
-		ParticipantName: req.Name,
+	return res, nil
+}
+
+// resolveJoin returns the room req joins and whether the participant joins it as an admin.
+// userID is the signed in user joining, empty when they're anonymous.
+func (s *Service) resolveJoin(ctx context.Context, req *schema.JoinRoomRequest, userID string) (string, bool, error) {
+	switch {
+	case req.RoomID != "" && req.TeamSlug != "":
+		return "", false, errBadRequest("give either room_id or team_slug, not both")
+	case req.RoomID != "":
+		return req.RoomID, false, nil
+	case req.TeamSlug == "":
+		return "", false, errBadRequest("room_id or team_slug is required")
 	}
 
-	err = s.snsClient.Publish(ctx, schema.ParticipantJoined, msg)
+	team, err := s.ddbrepository.FindTeam(ctx, req.TeamSlug)
 	if err != nil {
-		log.Errorf("error publishing participant joined to sns: %w", err)
-		return lambdaresponses.Respond500()
+		if errors.Is(err, ddbrepository.ErrNotFound) {
+			return "", false, errTeamNotFound
+		}
+
+		return "", false, fmt.Errorf("error finding team: %w", err)
 	}
 
-	res := schema.JoinRoomResponse{
-		AccessToken: token,
+	if team.CurrentRoomID == "" {
+		return "", false, errBadRequest("team has no session open")
 	}
 
-	return lambdaresponses.Respond200(res)
+	// Signed in members are recognised whatever name they join with
+	if userID != "" {
+		if member := team.MemberByUser(userID); member != nil {
+			return team.CurrentRoomID, member.Role == types.RoleAdmin, nil
+		}
+	}
+
+	// Names aren't authenticated, so the admin role also needs the team's key or anyone could
+	// take an admin's name
+	isAdmin := team.RoleOf(req.Name) == types.RoleAdmin && req.TeamKey != "" && team.KeyMatches(req.TeamKey)
+
+	return team.CurrentRoomID, isAdmin, nil
 }
@@ -0,0 +1,157 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/jponc/estimatex-serverless/api/schema"
+	"github.com/jponc/estimatex-serverless/internal/repository/ddbrepository"
+	"github.com/jponc/estimatex-serverless/internal/types"
+	"github.com/jponc/estimatex-serverless/pkg/validator"
+)
+
+// RevoteRound starts a new round on the same story after the revealed one has been discussed,
+// its reveal is compared with the revealed round
+func (s *Service) RevoteRound(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	return s.endpoint(nil, func(ctx context.Context, claims *Claims) (interface{}, error) {
+		return s.revoteRound(ctx, claims)
+	}, requireClients(s.ddbrepository != nil), s.withClaims, requireAdmin)(ctx, request)
+}
+
+func (s *Service) revoteRound(ctx context.Context, claims *Claims) (*schema.RevoteRoundResponse, error) {
+	room, err := s.ddbrepository.FindRoom(ctx, claims.RoomID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get room: %w", err)
+	}
+
+	parent := room.CurrentRound()
+
+	_, err = s.ddbrepository.FindRound(ctx, claims.RoomID, parent)
+	if err != nil {
+		if errors.Is(err, ddbrepository.ErrNotFound) {
+			return nil, errBadRequest("votes must be revealed before a re-vote")
+		}
+
+		return nil, fmt.Errorf("failed to get round: %w", err)
+	}
+
+	err = s.startRound(ctx, room, parent)
+	if err != nil {
+		return nil, err
+	}
+
+	return &schema.RevoteRoundResponse{}, nil
+}
+
+// SetFinalEstimate records the estimate the host settled on for the revealed round
+func (s *Service) SetFinalEstimate(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	req := &schema.SetFinalEstimateRequest{}
+
+	return s.endpoint(req, func(ctx context.Context, claims *Claims) (interface{}, error) {
+		return s.setFinalEstimate(ctx, claims, req)
+	}, requireClients(s.ddbrepository != nil), s.withClaims, requireAdmin)(ctx, request)
+}
+
+func (s *Service) setFinalEstimate(ctx context.Context, claims *Claims, req *schema.SetFinalEstimateRequest) (*schema.SetFinalEstimateResponse, error) {
+	room, err := s.ddbrepository.FindRoom(ctx, claims.RoomID)
+	if err != nil {
+		if errors.Is(err, ddbrepository.ErrNotFound) {
+			return nil, errRoomNotFound
+		}
+
+		return nil, fmt.Errorf("failed to get room: %w", err)
+	}
+
+	if !req.Override && !room.HasCard(req.Estimate) {
+		return nil, errValidation(validator.Errors{
+			{
+				Field:   "estimate",
+				Rule:    "deck",
+				Message: "estimate must be one of the room's cards unless it's an override",
+			},
+		})
+	}
+
+	round, err := s.ddbrepository.FindRound(ctx, claims.RoomID, room.CurrentRound())
+	if err != nil {
+		if errors.Is(err, ddbrepository.ErrNotFound) {
+			return nil, errBadRequest("votes must be revealed before the estimate is finalized")
+		}
+
+		return nil, fmt.Errorf("failed to get round: %w", err)
+	}
+
+	msg := schema.EstimateFinalizedMessage{
+		Round:    round.Number,
+		Estimate: req.Estimate,
+		Override: req.Override,
+	}
+
+	event, err := s.newEvent(ctx, schema.EstimateFinalized, claims.RoomID, msg)
+	if err != nil {
+		return nil, fmt.Errorf("error creating estimate finalized event: %w", err)
+	}
+
+	err = s.ddbrepository.SetFinalEstimate(ctx, claims.RoomID, round.Number, req.Estimate, req.Override, event)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set final estimate: %w", err)
+	}
+
+	round.FinalEstimate = req.Estimate
+	round.FinalEstimateOverride = req.Override
+	round.FinalizedAt = event.Timestamp
+
+	res := &schema.SetFinalEstimateResponse{
+		Round: *round,
+	}
+
+	return res, nil
+}
+
+// FindRounds returns the room's revealed rounds
+func (s *Service) FindRounds(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	return s.endpoint(nil, func(ctx context.Context, claims *Claims) (interface{}, error) {
+		return s.findRounds(ctx, claims)
+	}, requireClients(s.ddbrepository != nil), s.withClaims)(ctx, request)
+}
+
+func (s *Service) findRounds(ctx context.Context, claims *Claims) (*schema.FindRoundsResponse, error) {
+	rounds, err := s.ddbrepository.FindRounds(ctx, claims.RoomID)
+	if err != nil {
+		return nil, fmt.Errorf("error finding rounds: %w", err)
+	}
+
+	res := &schema.FindRoundsResponse{
+		Rounds: rounds,
+	}
+
+	return res, nil
+}
+
+// startRound clears every vote and moves the room on to its next round, parentRound is the round
+// being re-voted or 0 for a fresh round
+func (s *Service) startRound(ctx context.Context, room *types.Room, parentRound int) error {
+	participants, err := s.ddbrepository.FindParticipants(ctx, room.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get participants: %w", err)
+	}
+
+	msg := schema.ResetVotesMessage{
+		Round:       room.CurrentRound() + 1,
+		ParentRound: parentRound,
+	}
+
+	event, err := s.newEvent(ctx, schema.ResetVotes, room.ID, msg)
+	if err != nil {
+		return fmt.Errorf("error creating reset votes event: %w", err)
+	}
+
+	err = s.ddbrepository.ResetVotes(ctx, room.ID, msg.Round, msg.ParentRound, *participants, room.Sequence, event)
+	if err != nil {
+		return fmt.Errorf("failed to reset votes: %w", err)
+	}
+
+	return nil
+}
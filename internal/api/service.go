@@ -2,44 +2,90 @@ package api
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/jponc/estimatex-serverless/api/schema"
 	"github.com/jponc/estimatex-serverless/internal/auth"
+	"github.com/jponc/estimatex-serverless/internal/integrations"
+	"github.com/jponc/estimatex-serverless/internal/ratelimit"
 	"github.com/jponc/estimatex-serverless/internal/repository/ddbrepository"
+	"github.com/jponc/estimatex-serverless/internal/roomid"
 	"github.com/jponc/estimatex-serverless/pkg/lambdaresponses"
-	"github.com/jponc/estimatex-serverless/pkg/sns"
+	"github.com/jponc/estimatex-serverless/pkg/logger"
+	"github.com/jponc/estimatex-serverless/pkg/metrics"
+	"github.com/jponc/estimatex-serverless/pkg/validator"
 )
 
 type Service struct {
 	ddbrepository *ddbrepository.Repository
-	snsClient     *sns.Client
 	authClient    *auth.Client
+	roomIDs       *roomid.Generator
+	corsPolicy    *lambdaresponses.CORSPolicy
+	metrics       *metrics.Recorder
+	trackers      *integrations.Connector
+	identities    *auth.OIDCVerifier
+	limiter       *ratelimit.Limiter
+	validator     *validator.Validator
+}
+
+// Options are the dependencies of the service, the ones an endpoint doesn't use can be left nil
+type Options struct {
+	Repository *ddbrepository.Repository
+	AuthClient *auth.Client
+	RoomIDs    *roomid.Generator
+	CORSPolicy *lambdaresponses.CORSPolicy
+	Metrics    *metrics.Recorder
+	Trackers   *integrations.Connector
+	Identities *auth.OIDCVerifier
 }
 
 // NewService instantiates a new service
-func NewService(ddbrepository *ddbrepository.Repository, snsClient *sns.Client, authClient *auth.Client) *Service {
-	return &Service{
-		ddbrepository: ddbrepository,
-		snsClient:     snsClient,
-		authClient:    authClient,
+func NewService(opts Options) *Service {
+	s := &Service{
+		ddbrepository: opts.Repository,
+		authClient:    opts.AuthClient,
+		roomIDs:       opts.RoomIDs,
+		corsPolicy:    opts.CORSPolicy,
+		metrics:       opts.Metrics,
+		trackers:      opts.Trackers,
+		identities:    opts.Identities,
+		validator:     validator.New(schema.ValidationPatterns),
+	}
+
+	// Rate limit buckets live in the room table, without it requests aren't limited
+	if opts.Repository != nil {
+		s.limiter = ratelimit.NewLimiter(opts.Repository)
 	}
+
+	return s
 }
 
 func (s *Service) SayHello(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
 	req := &schema.SayHelloRequest{}
 
-	err := json.Unmarshal([]byte(request.Body), req)
-	if err != nil {
-		return lambdaresponses.Respond400(fmt.Errorf("failed to unmarshal body"))
-	}
+	return s.endpoint(req, func(ctx context.Context, claims *Claims) (interface{}, error) {
+		return s.sayHello(ctx, claims, req)
+	})(ctx, request)
+}
 
+func (s *Service) sayHello(_ context.Context, _ *Claims, req *schema.SayHelloRequest) (*schema.SayHelloResponse, error) {
 	if req.Name == "Waldo" {
-		return lambdaresponses.Respond400(fmt.Errorf("cannot use name Waldo!"))
+		return nil, errBadRequest("cannot use name Waldo!")
 	}
 
 	message := fmt.Sprintf("Hello %s", req.Name)
-	return lambdaresponses.Respond200(schema.SayHelloResponse{Message: message})
+	return &schema.SayHelloResponse{Message: message}, nil
+}
+
+// newEvent wraps payload in an event envelope, the repository numbers it in the room's sequence
+// when it stores it and the outbox relay publishes it from there
+func (s *Service) newEvent(ctx context.Context, eventType, roomID string, payload interface{}) (*schema.Event, error) {
+	event, err := schema.NewEvent(eventType, roomID, 0, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	event.CorrelationID = logger.CorrelationID(ctx)
+	return event, nil
 }
@@ -0,0 +1,310 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/jponc/estimatex-serverless/api/schema"
+	"github.com/jponc/estimatex-serverless/internal/repository/ddbrepository"
+	"github.com/jponc/estimatex-serverless/internal/types"
+	"github.com/jponc/estimatex-serverless/pkg/validator"
+)
+
+func (s *Service) AddStory(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	req := &schema.AddStoryRequest{}
+
+	return s.endpoint(req, func(ctx context.Context, claims *Claims) (interface{}, error) {
+		return s.addStory(ctx, claims, req)
+	}, requireClients(s.ddbrepository != nil), s.withClaims, requireAdmin)(ctx, request)
+}
+
+func (s *Service) addStory(ctx context.Context, claims *Claims, req *schema.AddStoryRequest) (*schema.AddStoryResponse, error) {
+	stories, err := s.addStories(ctx, claims.RoomID, []schema.AddStoryRequest{*req})
+	if err != nil {
+		return nil, err
+	}
+
+	res := &schema.AddStoryResponse{
+		Story: stories[0],
+	}
+
+	return res, nil
+}
+
+func (s *Service) ImportStories(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	req := &schema.ImportStoriesRequest{}
+
+	return s.endpoint(req, func(ctx context.Context, claims *Claims) (interface{}, error) {
+		return s.importStories(ctx, claims, req)
+	}, requireClients(s.ddbrepository != nil), s.withClaims, requireAdmin)(ctx, request)
+}
+
+func (s *Service) importStories(ctx context.Context, claims *Claims, req *schema.ImportStoriesRequest) (*schema.ImportStoriesResponse, error) {
+	err := s.validateStories(req.Stories)
+	if err != nil {
+		return nil, err
+	}
+
+	stories, err := s.addStories(ctx, claims.RoomID, req.Stories)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &schema.ImportStoriesResponse{
+		Stories: stories,
+	}
+
+	return res, nil
+}
+
+// addStories appends the stories to the end of the room's queue
+func (s *Service) addStories(ctx context.Context, roomID string, reqs []schema.AddStoryRequest) ([]types.Story, error) {
+	room, err := s.ddbrepository.FindRoom(ctx, roomID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get room: %w", err)
+	}
+
+	stories := []types.Story{}
+	queue := append([]string{}, room.StoryQueue...)
+
+	for _, r := range reqs {
+		story, err := ddbrepository.NewStory(roomID, r.Title, r.Description, r.ExternalKey, r.URL)
+		if err != nil {
+			return nil, err
+		}
+
+		stories = append(stories, *story)
+		queue = append(queue, story.ID)
+	}
+
+	event, err := s.newEvent(ctx, schema.StoryQueueChanged, roomID, schema.StoryQueueChangedMessage{StoryIDs: queue})
+	if err != nil {
+		return nil, fmt.Errorf("error creating story queue changed event: %w", err)
+	}
+
+	err = s.ddbrepository.AddStories(ctx, roomID, stories, queue, room.Sequence, event)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add stories: %w", err)
+	}
+
+	return stories, nil
+}
+
+func (s *Service) ReorderStories(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	req := &schema.ReorderStoriesRequest{}
+
+	return s.endpoint(req, func(ctx context.Context, claims *Claims) (interface{}, error) {
+		return s.reorderStories(ctx, claims, req)
+	}, requireClients(s.ddbrepository != nil), s.withClaims, requireAdmin)(ctx, request)
+}
+
+func (s *Service) reorderStories(ctx context.Context, claims *Claims, req *schema.ReorderStoriesRequest) (*schema.ReorderStoriesResponse, error) {
+	room, err := s.ddbrepository.FindRoom(ctx, claims.RoomID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get room: %w", err)
+	}
+
+	if !samePermutation(room.StoryQueue, req.StoryIDs) {
+		return nil, errBadRequest("story_ids must list every queued story exactly once")
+	}
+
+	event, err := s.newEvent(ctx, schema.StoryQueueChanged, claims.RoomID, schema.StoryQueueChangedMessage{StoryIDs: req.StoryIDs})
+	if err != nil {
+		return nil, fmt.Errorf("error creating story queue changed event: %w", err)
+	}
+
+	err = s.ddbrepository.UpdateStoryQueue(ctx, claims.RoomID, req.StoryIDs, room.Sequence, event)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reorder stories: %w", err)
+	}
+
+	return &schema.ReorderStoriesResponse{}, nil
+}
+
+func (s *Service) RemoveStory(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	req := &schema.RemoveStoryRequest{}
+
+	return s.endpoint(req, func(ctx context.Context, claims *Claims) (interface{}, error) {
+		return s.removeStory(ctx, claims, req)
+	}, requireClients(s.ddbrepository != nil), s.withClaims, requireAdmin)(ctx, request)
+}
+
+func (s *Service) removeStory(ctx context.Context, claims *Claims, req *schema.RemoveStoryRequest) (*schema.RemoveStoryResponse, error) {
+	room, err := s.ddbrepository.FindRoom(ctx, claims.RoomID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get room: %w", err)
+	}
+
+	if req.StoryID == room.CurrentStoryID {
+		return nil, errBadRequest("can't remove the story being estimated")
+	}
+
+	queue := []string{}
+	for _, id := range room.StoryQueue {
+		if id != req.StoryID {
+			queue = append(queue, id)
+		}
+	}
+
+	if len(queue) == len(room.StoryQueue) {
+		return nil, errStoryNotFound
+	}
+
+	event, err := s.newEvent(ctx, schema.StoryQueueChanged, claims.RoomID, schema.StoryQueueChangedMessage{StoryIDs: queue})
+	if err != nil {
+		return nil, fmt.Errorf("error creating story queue changed event: %w", err)
+	}
+
+	err = s.ddbrepository.RemoveStory(ctx, claims.RoomID, req.StoryID, queue, room.Sequence, event)
+	if err != nil {
+		if errors.Is(err, ddbrepository.ErrNotFound) {
+			return nil, errStoryNotFound
+		}
+
+		return nil, fmt.Errorf("failed to remove story: %w", err)
+	}
+
+	return &schema.RemoveStoryResponse{}, nil
+}
+
+// NextStory moves the head of the queue into estimation and starts a fresh round for it
+func (s *Service) NextStory(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	return s.endpoint(nil, func(ctx context.Context, claims *Claims) (interface{}, error) {
+		return s.nextStory(ctx, claims)
+	}, requireClients(s.ddbrepository != nil), s.withClaims, requireAdmin)(ctx, request)
+}
+
+func (s *Service) nextStory(ctx context.Context, claims *Claims) (*schema.NextStoryResponse, error) {
+	room, err := s.ddbrepository.FindRoom(ctx, claims.RoomID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get room: %w", err)
+	}
+
+	participants, err := s.ddbrepository.FindParticipants(ctx, claims.RoomID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get participants: %w", err)
+	}
+
+	var story *types.Story
+	queue := []string{}
+
+	if len(room.StoryQueue) > 0 {
+		story, err = s.ddbrepository.FindStory(ctx, claims.RoomID, room.StoryQueue[0])
+		if err != nil {
+			return nil, fmt.Errorf("failed to get story: %w", err)
+		}
+		queue = append(queue, room.StoryQueue[1:]...)
+	}
+
+	msg := schema.StoryChangedMessage{
+		Story: story,
+		Round: room.CurrentRound() + 1,
+	}
+
+	event, err := s.newEvent(ctx, schema.StoryChanged, claims.RoomID, msg)
+	if err != nil {
+		return nil, fmt.Errorf("error creating story changed event: %w", err)
+	}
+
+	storyID := ""
+	if story != nil {
+		storyID = story.ID
+	}
+
+	err = s.ddbrepository.StartStory(ctx, claims.RoomID, storyID, queue, msg.Round, *participants, room.Sequence, event)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start story: %w", err)
+	}
+
+	res := &schema.NextStoryResponse{
+		Story: story,
+		Round: msg.Round,
+	}
+
+	return res, nil
+}
+
+// FindStories returns the story being estimated and the queue in order
+func (s *Service) FindStories(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	return s.endpoint(nil, func(ctx context.Context, claims *Claims) (interface{}, error) {
+		return s.findStories(ctx, claims)
+	}, requireClients(s.ddbrepository != nil), s.withClaims)(ctx, request)
+}
+
+func (s *Service) findStories(ctx context.Context, claims *Claims) (*schema.FindStoriesResponse, error) {
+	room, err := s.ddbrepository.FindRoom(ctx, claims.RoomID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get room: %w", err)
+	}
+
+	stories, err := s.ddbrepository.FindStories(ctx, claims.RoomID)
+	if err != nil {
+		return nil, fmt.Errorf("error finding stories: %w", err)
+	}
+
+	res := &schema.FindStoriesResponse{
+		Queue: []types.Story{},
+	}
+
+	if story, ok := stories[room.CurrentStoryID]; ok {
+		res.CurrentStory = &story
+	}
+
+	for _, id := range room.StoryQueue {
+		if story, ok := stories[id]; ok {
+			res.Queue = append(res.Queue, story)
+		}
+	}
+
+	return res, nil
+}
+
+// validateStories validates every imported story, reporting fields by their index
+func (s *Service) validateStories(stories []schema.AddStoryRequest) error {
+	verrs := validator.Errors{}
+
+	for i := range stories {
+		err := s.validator.Validate(&stories[i])
+
+		var storyErrs validator.Errors
+		if errors.As(err, &storyErrs) {
+			for _, f := range storyErrs {
+				f.Field = fmt.Sprintf("stories[%d].%s", i, f.Field)
+				verrs = append(verrs, f)
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(verrs) > 0 {
+		return errValidation(verrs)
+	}
+
+	return nil
+}
+
+// samePermutation reports whether ids holds exactly the IDs of queue in any order
+func samePermutation(queue, ids []string) bool {
+	if len(queue) != len(ids) {
+		return false
+	}
+
+	counts := map[string]int{}
+	for _, id := range queue {
+		counts[id]++
+	}
+
+	for _, id := range ids {
+		counts[id]--
+		if counts[id] < 0 {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,244 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/jponc/estimatex-serverless/api/schema"
+	"github.com/jponc/estimatex-serverless/internal/repository/ddbrepository"
+	"github.com/jponc/estimatex-serverless/internal/types"
+	"github.com/jponc/estimatex-serverless/pkg/validator"
+)
+
+// teamHistoryLimit is how many of the latest sessions and rounds FindTeamHistory returns
+const teamHistoryLimit = 50
+
+// CreateTeam reserves a slug the team's recurring sessions are hosted and joined under, the team
+// key it responds with is needed to manage the team and host its sessions
+func (s *Service) CreateTeam(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	req := &schema.CreateTeamRequest{}
+
+	return s.endpoint(req, func(ctx context.Context, claims *Claims) (interface{}, error) {
+		return s.createTeam(ctx, claims, req)
+	}, requireClients(s.ddbrepository != nil), s.rateLimit(createTeamLimit, bySourceIP))(ctx, request)
+}
+
+func (s *Service) createTeam(ctx context.Context, _ *Claims, req *schema.CreateTeamRequest) (*schema.CreateTeamResponse, error) {
+	err := validateDeck(req.Deck)
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := s.teamMembers(req.Members)
+	if err != nil {
+		return nil, err
+	}
+
+	key, keyHash, err := ddbrepository.NewTeamKey()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	team := ddbrepository.StoredTeam{
+		Team: types.Team{
+			Slug:        req.Slug,
+			Name:        req.Name,
+			Deck:        req.Deck,
+			DefaultRole: defaultRole(req.DefaultRole),
+			Members:     members,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		},
+		KeyHash: keyHash,
+	}
+
+	err = s.ddbrepository.CreateTeam(ctx, team)
+	if err != nil {
+		if errors.Is(err, ddbrepository.ErrAlreadyExists) {
+			return nil, errTeamExists
+		}
+
+		return nil, fmt.Errorf("failed to create team: %w", err)
+	}
+
+	return &schema.CreateTeamResponse{Team: team.Team, TeamKey: key}, nil
+}
+
+func (s *Service) UpdateTeam(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	req := &schema.UpdateTeamRequest{}
+
+	return s.endpoint(req, func(ctx context.Context, claims *Claims) (interface{}, error) {
+		return s.updateTeam(ctx, claims, req)
+	}, requireClients(s.ddbrepository != nil))(ctx, request)
+}
+
+func (s *Service) updateTeam(ctx context.Context, _ *Claims, req *schema.UpdateTeamRequest) (*schema.UpdateTeamResponse, error) {
+	err := validateDeck(req.Deck)
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := s.teamMembers(req.Members)
+	if err != nil {
+		return nil, err
+	}
+
+	stored, err := s.findTeamWithKey(ctx, req.Slug, req.TeamKey)
+	if err != nil {
+		return nil, err
+	}
+
+	team := stored.Team
+	team.Name = req.Name
+	team.Deck = req.Deck
+	team.DefaultRole = defaultRole(req.DefaultRole)
+	team.Members = members
+	team.UpdatedAt = time.Now()
+
+	err = s.ddbrepository.UpdateTeam(ctx, team)
+	if err != nil {
+		if errors.Is(err, ddbrepository.ErrNotFound) {
+			return nil, errTeamNotFound
+		}
+
+		return nil, fmt.Errorf("failed to update team: %w", err)
+	}
+
+	return &schema.UpdateTeamResponse{Team: team}, nil
+}
+
+// FindTeam shows a team and its current session to anyone with its slug
+func (s *Service) FindTeam(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	req := &schema.FindTeamRequest{}
+
+	return s.endpoint(req, func(ctx context.Context, claims *Claims) (interface{}, error) {
+		return s.findTeam(ctx, claims, req)
+	}, requireClients(s.ddbrepository != nil), s.rateLimit(joinRoomLimit, bySourceIP))(ctx, request)
+}
+
+func (s *Service) findTeam(ctx context.Context, _ *Claims, req *schema.FindTeamRequest) (*schema.FindTeamResponse, error) {
+	team, err := s.ddbrepository.FindTeam(ctx, req.Slug)
+	if err != nil {
+		if errors.Is(err, ddbrepository.ErrNotFound) {
+			return nil, errTeamNotFound
+		}
+
+		return nil, fmt.Errorf("failed to find team: %w", err)
+	}
+
+	return &schema.FindTeamResponse{Team: team.Team}, nil
+}
+
+// FindTeamHistory returns the latest sessions and rounds of the team the caller's room belongs to
+func (s *Service) FindTeamHistory(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	return s.endpoint(nil, func(ctx context.Context, claims *Claims) (interface{}, error) {
+		return s.findTeamHistory(ctx, claims)
+	}, requireClients(s.ddbrepository != nil), s.withClaims)(ctx, request)
+}
+
+func (s *Service) findTeamHistory(ctx context.Context, claims *Claims) (*schema.FindTeamHistoryResponse, error) {
+	room, err := s.ddbrepository.FindRoom(ctx, claims.RoomID)
+	if err != nil {
+		if errors.Is(err, ddbrepository.ErrNotFound) {
+			return nil, errRoomNotFound
+		}
+
+		return nil, fmt.Errorf("failed to get room: %w", err)
+	}
+
+	if room.TeamSlug == "" {
+		return nil, errBadRequest("room isn't a team session")
+	}
+
+	team, err := s.ddbrepository.FindTeam(ctx, room.TeamSlug)
+	if err != nil {
+		if errors.Is(err, ddbrepository.ErrNotFound) {
+			return nil, errTeamNotFound
+		}
+
+		return nil, fmt.Errorf("failed to find team: %w", err)
+	}
+
+	history, err := s.ddbrepository.FindTeamHistory(ctx, room.TeamSlug, teamHistoryLimit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get team history: %w", err)
+	}
+
+	res := &schema.FindTeamHistoryResponse{
+		Team:     team.Team,
+		Sessions: history.Sessions,
+		Rounds:   history.Rounds,
+	}
+
+	return res, nil
+}
+
+// findTeamWithKey returns the team with slug, a 403 unless key is the team's key
+func (s *Service) findTeamWithKey(ctx context.Context, slug, key string) (*ddbrepository.StoredTeam, error) {
+	team, err := s.ddbrepository.FindTeam(ctx, slug)
+	if err != nil {
+		if errors.Is(err, ddbrepository.ErrNotFound) {
+			return nil, errTeamNotFound
+		}
+
+		return nil, fmt.Errorf("failed to find team: %w", err)
+	}
+
+	if key == "" || !team.KeyMatches(key) {
+		return nil, errNotAllowed
+	}
+
+	return team, nil
+}
+
+// teamMembers validates each member like a TeamMemberRequest, names must be unique
+func (s *Service) teamMembers(reqs []schema.TeamMemberRequest) ([]types.TeamMember, error) {
+	verrs := validator.Errors{}
+	members := []types.TeamMember{}
+	seen := map[string]bool{}
+
+	for i := range reqs {
+		err := s.validator.Validate(&reqs[i])
+
+		var memberErrs validator.Errors
+		if errors.As(err, &memberErrs) {
+			for _, f := range memberErrs {
+				f.Field = fmt.Sprintf("members[%d].%s", i, f.Field)
+				verrs = append(verrs, f)
+			}
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if seen[reqs[i].Name] {
+			field := fmt.Sprintf("members[%d].name", i)
+			verrs = append(verrs, validator.FieldError{Field: field, Rule: "unique", Message: fmt.Sprintf("%s is a duplicate member", field)})
+			continue
+		}
+		seen[reqs[i].Name] = true
+
+		members = append(members, types.TeamMember{Name: reqs[i].Name, Role: reqs[i].Role, UserID: reqs[i].UserID})
+	}
+
+	if len(verrs) > 0 {
+		return nil, errValidation(verrs)
+	}
+
+	return members, nil
+}
+
+// defaultRole is the role non-members join with, voter unless the team says otherwise
+func defaultRole(role string) string {
+	if role == "" {
+		return types.RoleVoter
+	}
+
+	return role
+}
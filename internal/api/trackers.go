@@ -0,0 +1,77 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/jponc/estimatex-serverless/api/schema"
+	"github.com/jponc/estimatex-serverless/internal/integrations"
+	"github.com/jponc/estimatex-serverless/internal/types"
+	"github.com/jponc/estimatex-serverless/pkg/lambdaresponses"
+	"github.com/jponc/estimatex-serverless/pkg/logger"
+)
+
+// ConnectTracker connects the room to an issue tracker so issues can be searched and final
+// estimates are written back to them
+func (s *Service) ConnectTracker(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	req := &schema.ConnectTrackerRequest{}
+
+	return s.endpoint(req, func(ctx context.Context, claims *Claims) (interface{}, error) {
+		return s.connectTracker(ctx, claims, req)
+	}, requireClients(s.ddbrepository != nil && s.trackers != nil), s.withClaims, requireAdmin)(ctx, request)
+}
+
+func (s *Service) connectTracker(ctx context.Context, claims *Claims, req *schema.ConnectTrackerRequest) (*schema.ConnectTrackerResponse, error) {
+	integration := types.TrackerIntegration{
+		Kind:        req.Kind,
+		BaseURL:     req.BaseURL,
+		Project:     req.Project,
+		PointsField: req.PointsField,
+	}
+
+	credentials := integrations.Credentials{
+		Username: req.Username,
+		Token:    req.Token,
+	}
+
+	saved, err := s.trackers.Save(ctx, claims.RoomID, integration, credentials)
+	if errors.Is(err, integrations.ErrInvalidIntegration) {
+		return nil, errBadRequest(err.Error())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect tracker: %w", err)
+	}
+
+	return &schema.ConnectTrackerResponse{Integration: *saved}, nil
+}
+
+// SearchTrackerIssues searches the connected tracker for issues to add as stories
+func (s *Service) SearchTrackerIssues(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	req := &schema.SearchTrackerIssuesRequest{}
+
+	return s.endpoint(req, func(ctx context.Context, claims *Claims) (interface{}, error) {
+		return s.searchTrackerIssues(ctx, claims, req)
+	}, requireClients(s.ddbrepository != nil && s.trackers != nil), s.withClaims, requireAdmin)(ctx, request)
+}
+
+func (s *Service) searchTrackerIssues(ctx context.Context, claims *Claims, req *schema.SearchTrackerIssuesRequest) (*schema.SearchTrackerIssuesResponse, error) {
+	tracker, err := s.trackers.Connect(ctx, claims.RoomID)
+	if errors.Is(err, integrations.ErrNotConnected) {
+		return nil, errTrackerNotConnected
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to tracker: %w", err)
+	}
+
+	issues, err := tracker.FindIssues(ctx, req.Query)
+	if err != nil {
+		// Usually bad credentials or query syntax, which the admin can fix
+		logger.FromContext(ctx).Warnf("tracker search failed: %v", err)
+		return nil, lambdaresponses.NewAPIError(http.StatusBadGateway, schema.ErrCodeTrackerFailed, "tracker search failed, check the query and credentials")
+	}
+
+	return &schema.SearchTrackerIssuesResponse{Issues: issues}, nil
+}
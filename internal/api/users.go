@@ -0,0 +1,74 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/jponc/estimatex-serverless/api/schema"
+	"github.com/jponc/estimatex-serverless/internal/auth"
+	"github.com/jponc/estimatex-serverless/internal/types"
+)
+
+// Login exchanges an ID token from the identity provider for a user token. Signing in is optional,
+// rooms can still be hosted and joined anonymously.
+func (s *Service) Login(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	req := &schema.LoginRequest{}
+
+	return s.endpoint(req, func(ctx context.Context, claims *Claims) (interface{}, error) {
+		return s.login(ctx, claims, req)
+	}, requireClients(s.ddbrepository != nil && s.authClient != nil), s.rateLimit(loginLimit, bySourceIP))(ctx, request)
+}
+
+func (s *Service) login(ctx context.Context, _ *Claims, req *schema.LoginRequest) (*schema.LoginResponse, error) {
+	if s.identities == nil {
+		return nil, errLoginDisabled
+	}
+
+	identity, err := s.identities.Verify(ctx, req.IDToken)
+	if err != nil {
+		if errors.Is(err, auth.ErrInvalidIDToken) {
+			return nil, errInvalidIDToken
+		}
+
+		return nil, fmt.Errorf("failed to verify ID token: %w", err)
+	}
+
+	now := time.Now()
+
+	user, err := s.ddbrepository.SaveUser(ctx, types.User{
+		ID:          identity.UserID(),
+		Issuer:      identity.Issuer,
+		Subject:     identity.Subject,
+		Email:       identity.Email,
+		Name:        identity.Name,
+		CreatedAt:   now,
+		LastLoginAt: now,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save user: %w", err)
+	}
+
+	token, err := s.authClient.CreateUserToken(*user)
+	if err != nil {
+		return nil, fmt.Errorf("error creating user token: %w", err)
+	}
+
+	return &schema.LoginResponse{User: *user, UserToken: token}, nil
+}
+
+// userID returns the user signed in with userToken, empty for anonymous requests without one
+func (s *Service) userID(userToken string) (string, error) {
+	if userToken == "" {
+		return "", nil
+	}
+
+	claims, err := s.authClient.GetUserClaims(userToken)
+	if err != nil {
+		return "", errInvalidUserToken
+	}
+
+	return claims.UserID, nil
+}
@@ -2,160 +2,167 @@ package api
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/jponc/estimatex-serverless/api/schema"
-	"github.com/jponc/estimatex-serverless/pkg/lambdaresponses"
-	log "github.com/sirupsen/logrus"
+	"github.com/jponc/estimatex-serverless/internal/repository/ddbrepository"
+	"github.com/jponc/estimatex-serverless/internal/stats"
+	"github.com/jponc/estimatex-serverless/internal/types"
+	"github.com/jponc/estimatex-serverless/pkg/metrics"
+	"github.com/jponc/estimatex-serverless/pkg/validator"
 )
 
 func (s *Service) CastVote(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
-	if s.snsClient == nil {
-		log.Errorf("snsClient or authClient is nil")
-		return lambdaresponses.Respond500()
-	}
-
 	req := &schema.CastVoteRequest{}
 
-	roomID, ok := request.RequestContext.Authorizer["RoomID"].(string)
-	if !ok {
-		return lambdaresponses.Respond500()
-	}
-
-	name, ok := request.RequestContext.Authorizer["Name"].(string)
-	if !ok {
-		return lambdaresponses.Respond500()
-	}
+	return s.endpoint(req, func(ctx context.Context, claims *Claims) (interface{}, error) {
+		return s.castVote(ctx, claims, req)
+	}, requireClients(s.ddbrepository != nil), s.withClaims, s.rateLimit(castVoteLimit, byParticipant), s.rateLimit(roomVoteLimit, byRoom))(ctx, request)
+}
 
-	err := json.Unmarshal([]byte(request.Body), req)
+func (s *Service) castVote(ctx context.Context, claims *Claims, req *schema.CastVoteRequest) (*schema.CastVoteResponse, error) {
+	room, err := s.ddbrepository.FindRoom(ctx, claims.RoomID)
 	if err != nil {
-		return lambdaresponses.Respond400(fmt.Errorf("failed to unmarshal body"))
-	}
+		if errors.Is(err, ddbrepository.ErrNotFound) {
+			return nil, errRoomNotFound
+		}
 
-	if req.Vote == "" {
-		return lambdaresponses.Respond400(fmt.Errorf("vote can't be blank"))
+		return nil, fmt.Errorf("failed to get room: %w", err)
 	}
 
-	p, err := s.ddbrepository.FindParticipant(ctx, roomID, name)
-	if err != nil {
-		log.Errorf("failed to get participant: %v", err)
-		return lambdaresponses.Respond500()
+	if !room.HasCard(req.Vote) {
+		return nil, errValidation(validator.Errors{
+			{
+				Field:   "vote",
+				Rule:    "deck",
+				Message: "vote must be one of the room's cards",
+			},
+		})
 	}
 
-	err = s.ddbrepository.CastVote(ctx, p, req.Vote)
+	p, err := s.ddbrepository.FindParticipant(ctx, claims.RoomID, claims.Name)
 	if err != nil {
-		log.Errorf("failed to cast vote: %v", err)
-		return lambdaresponses.Respond500()
+		if errors.Is(err, ddbrepository.ErrNotFound) {
+			return nil, errParticipantNotFound
+		}
+
+		return nil, fmt.Errorf("failed to get participant: %w", err)
 	}
 
 	msg := schema.ParticipantVotedMessage{
-		RoomID:          roomID,
-		ParticipantName: name,
+		ParticipantName: claims.Name,
 		Vote:            req.Vote,
 	}
 
-	err = s.snsClient.Publish(ctx, schema.ParticipantVoted, msg)
+	event, err := s.newEvent(ctx, schema.ParticipantVoted, claims.RoomID, msg)
 	if err != nil {
-		log.Errorf("error publishing participant voted to sns: %w", err)
-		return lambdaresponses.Respond500()
+		return nil, fmt.Errorf("error creating participant voted event: %w", err)
 	}
 
-	res := schema.CastVoteResponse{}
+	err = s.ddbrepository.CastVote(ctx, p, req.Vote, req.Comment, event)
+	if err != nil {
+		return nil, fmt.Errorf("failed to cast vote: %w", err)
+	}
 
-	return lambdaresponses.Respond200(res)
+	s.metrics.Emit(ctx, metrics.Count(metricVotesCast, 1))
+
+	return &schema.CastVoteResponse{}, nil
 }
 
 func (s *Service) RevealVotes(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
-	if s.snsClient == nil {
-		log.Errorf("snsClient or authClient is nil")
-		return lambdaresponses.Respond500()
+	return s.endpoint(nil, func(ctx context.Context, claims *Claims) (interface{}, error) {
+		return s.revealVotes(ctx, claims)
+	}, requireClients(s.ddbrepository != nil), s.withClaims, requireAdmin)(ctx, request)
+}
+
+func (s *Service) revealVotes(ctx context.Context, claims *Claims) (*schema.RevealVotesResponse, error) {
+	room, err := s.ddbrepository.FindRoom(ctx, claims.RoomID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get room: %w", err)
+	}
+
+	participants, err := s.ddbrepository.FindParticipants(ctx, claims.RoomID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get participants: %w", err)
 	}
 
-	isAdmin, ok := request.RequestContext.Authorizer["IsAdmin"].(string)
-	if !ok {
-		log.Errorf("no is admin")
-		return lambdaresponses.Respond500()
+	// Rooms created before rounds were tracked have no round start
+	roundStartedAt := room.RoundStartedAt
+	if roundStartedAt.IsZero() {
+		roundStartedAt = room.CreatedAt
 	}
 
-	if isAdmin != "true" {
-		return lambdaresponses.Respond400(fmt.Errorf("not allowed"))
+	round := &types.Round{
+		RoomID:      claims.RoomID,
+		Number:      room.CurrentRound(),
+		ParentRound: room.ParentRound,
+		StartedAt:   roundStartedAt,
+		StoryID:     room.CurrentStoryID,
+		Stats:       stats.ComputeRound(roundStartedAt, *participants),
+		TeamSlug:    room.TeamSlug,
 	}
 
-	roomID, ok := request.RequestContext.Authorizer["RoomID"].(string)
-	if !ok {
-		log.Errorf("no room id")
-		return lambdaresponses.Respond500()
+	if room.ParentRound > 0 {
+		parent, err := s.ddbrepository.FindRound(ctx, claims.RoomID, room.ParentRound)
+		if err != nil && !errors.Is(err, ddbrepository.ErrNotFound) {
+			return nil, fmt.Errorf("failed to get parent round: %w", err)
+		}
+		if parent != nil {
+			comparison := stats.CompareRounds(*parent, round.Stats)
+			round.Comparison = &comparison
+		}
 	}
 
 	msg := schema.RevealVotesMessage{
-		RoomID: roomID,
+		Round:      round.Number,
+		Stats:      round.Stats,
+		Comparison: round.Comparison,
 	}
 
-	err := s.snsClient.Publish(ctx, schema.RevealVotes, msg)
+	event, err := s.newEvent(ctx, schema.RevealVotes, claims.RoomID, msg)
 	if err != nil {
-		log.Errorf("error doing RevealVotes to sns: %w", err)
-		return lambdaresponses.Respond500()
+		return nil, fmt.Errorf("error creating reveal votes event: %w", err)
 	}
 
-	res := schema.RevealVotesResponse{}
+	round.RevealedAt = event.Timestamp
 
-	return lambdaresponses.Respond200(res)
-}
-
-func (s *Service) ResetVotes(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
-	if s.snsClient == nil {
-		log.Errorf("snsClient is nil")
-		return lambdaresponses.Respond500()
-	}
+	err = s.ddbrepository.RevealRound(ctx, round, room.Sequence, event)
+	if err != nil {
+		if errors.Is(err, ddbrepository.ErrAlreadyExists) {
+			return nil, errRoundFinalized
+		}
 
-	if s.ddbrepository == nil {
-		log.Errorf("ddbrepository is nil")
-		return lambdaresponses.Respond500()
+		return nil, fmt.Errorf("error revealing round: %w", err)
 	}
 
-	isAdmin, ok := request.RequestContext.Authorizer["IsAdmin"].(string)
-	if !ok {
-		log.Errorf("no is admin")
-		return lambdaresponses.Respond500()
-	}
+	s.metrics.Emit(ctx,
+		metrics.Count(metricReveals, 1),
+		metrics.Count(metricVotesPerRound, msg.Stats.VoteCount),
+		metrics.Count(metricParticipantsPerRoom, len(*participants)),
+		metrics.Seconds(metricTimeToReveal, event.Timestamp.Sub(roundStartedAt)),
+	)
 
-	if isAdmin != "true" {
-		return lambdaresponses.Respond400(fmt.Errorf("not allowed"))
-	}
+	return &schema.RevealVotesResponse{}, nil
+}
 
-	roomID, ok := request.RequestContext.Authorizer["RoomID"].(string)
-	if !ok {
-		log.Errorf("no room id")
-		return lambdaresponses.Respond500()
-	}
+func (s *Service) ResetVotes(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	return s.endpoint(nil, func(ctx context.Context, claims *Claims) (interface{}, error) {
+		return s.resetVotes(ctx, claims)
+	}, requireClients(s.ddbrepository != nil), s.withClaims, requireAdmin)(ctx, request)
+}
 
-	participants, err := s.ddbrepository.FindParticipants(ctx, roomID)
+func (s *Service) resetVotes(ctx context.Context, claims *Claims) (*schema.ResetVotesResponse, error) {
+	room, err := s.ddbrepository.FindRoom(ctx, claims.RoomID)
 	if err != nil {
-		log.Errorf("failed to get participants: %v", err)
-	}
-
-	for _, p := range *participants {
-		err := s.ddbrepository.CastVote(ctx, &p, "")
-		if err != nil {
-			log.Errorf("failed to cast vote: %w", err)
-			return lambdaresponses.Respond500()
-		}
+		return nil, fmt.Errorf("failed to get room: %w", err)
 	}
 
-	// Send SNS
-	msg := schema.ResetVotesMessage{
-		RoomID: roomID,
-	}
-
-	err = s.snsClient.Publish(ctx, schema.ResetVotes, msg)
+	err = s.startRound(ctx, room, 0)
 	if err != nil {
-		log.Errorf("error doing RevealVotes to sns: %w", err)
-		return lambdaresponses.Respond500()
+		return nil, err
 	}
 
-	res := schema.ResetVotesResponse{}
-
-	return lambdaresponses.Respond200(res)
+	return &schema.ResetVotesResponse{}, nil
 }
@@ -0,0 +1,122 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/jponc/estimatex-serverless/api/schema"
+	"github.com/jponc/estimatex-serverless/internal/repository/ddbrepository"
+	"github.com/jponc/estimatex-serverless/internal/types"
+)
+
+// maxWebhooksPerRoom limits the endpoints every room event is delivered to
+const maxWebhooksPerRoom = 5
+
+// recentDeliveries is how many deliveries FindWebhooks returns
+const recentDeliveries = 50
+
+// RegisterWebhook subscribes an HTTPS endpoint to the room's events
+func (s *Service) RegisterWebhook(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	req := &schema.RegisterWebhookRequest{}
+
+	return s.endpoint(req, func(ctx context.Context, claims *Claims) (interface{}, error) {
+		return s.registerWebhook(ctx, claims, req)
+	}, requireClients(s.ddbrepository != nil), s.withClaims, requireAdmin)(ctx, request)
+}
+
+func (s *Service) registerWebhook(ctx context.Context, claims *Claims, req *schema.RegisterWebhookRequest) (*schema.RegisterWebhookResponse, error) {
+	for _, t := range req.EventTypes {
+		if !isEventType(t) {
+			return nil, errBadRequest(fmt.Sprintf("unknown event type %s", t))
+		}
+	}
+
+	hooks, err := s.ddbrepository.FindWebhooks(ctx, claims.RoomID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhooks: %w", err)
+	}
+
+	if len(hooks) >= maxWebhooksPerRoom {
+		return nil, errBadRequest(fmt.Sprintf("a room can have at most %d webhooks", maxWebhooksPerRoom))
+	}
+
+	hook, err := ddbrepository.NewWebhook(req.URL, req.EventTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.ddbrepository.SaveWebhook(ctx, claims.RoomID, *hook)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save webhook: %w", err)
+	}
+
+	res := &schema.RegisterWebhookResponse{
+		Webhook: hook.Webhook,
+		Secret:  hook.Secret,
+	}
+
+	return res, nil
+}
+
+func (s *Service) RemoveWebhook(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	req := &schema.RemoveWebhookRequest{}
+
+	return s.endpoint(req, func(ctx context.Context, claims *Claims) (interface{}, error) {
+		return s.removeWebhook(ctx, claims, req)
+	}, requireClients(s.ddbrepository != nil), s.withClaims, requireAdmin)(ctx, request)
+}
+
+func (s *Service) removeWebhook(ctx context.Context, claims *Claims, req *schema.RemoveWebhookRequest) (*schema.RemoveWebhookResponse, error) {
+	err := s.ddbrepository.RemoveWebhook(ctx, claims.RoomID, req.WebhookID)
+	if err != nil {
+		if errors.Is(err, ddbrepository.ErrNotFound) {
+			return nil, errWebhookNotFound
+		}
+
+		return nil, fmt.Errorf("failed to remove webhook: %w", err)
+	}
+
+	return &schema.RemoveWebhookResponse{}, nil
+}
+
+// FindWebhooks returns the room's webhooks and their most recent deliveries
+func (s *Service) FindWebhooks(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	return s.endpoint(nil, func(ctx context.Context, claims *Claims) (interface{}, error) {
+		return s.findWebhooks(ctx, claims)
+	}, requireClients(s.ddbrepository != nil), s.withClaims, requireAdmin)(ctx, request)
+}
+
+func (s *Service) findWebhooks(ctx context.Context, claims *Claims) (*schema.FindWebhooksResponse, error) {
+	hooks, err := s.ddbrepository.FindWebhooks(ctx, claims.RoomID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhooks: %w", err)
+	}
+
+	deliveries, err := s.ddbrepository.FindWebhookDeliveries(ctx, claims.RoomID, recentDeliveries)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook deliveries: %w", err)
+	}
+
+	res := &schema.FindWebhooksResponse{
+		Webhooks:   []types.Webhook{},
+		Deliveries: deliveries,
+	}
+
+	for _, hook := range hooks {
+		res.Webhooks = append(res.Webhooks, hook.Webhook)
+	}
+
+	return res, nil
+}
+
+func isEventType(eventType string) bool {
+	for _, t := range schema.EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+
+	return false
+}
@@ -1,6 +1,7 @@
 package auth
 
 import (
+	"errors"
 	"time"
 
 	jwt "github.com/dgrijalva/jwt-go"
@@ -11,9 +12,26 @@ type ParticipantClaims struct {
 	RoomID  string `json:"room_id"`
 	Name    string `json:"name"`
 	IsAdmin bool   `json:"is_admin"`
+	// UserID is set when the participant joined signed in
+	UserID string `json:"user_id,omitempty"`
 	jwt.StandardClaims
 }
 
+// UserClaims identify a signed in user to HostRoom and JoinRoom, they don't grant access to a room
+type UserClaims struct {
+	UserID string `json:"user_id"`
+	jwt.StandardClaims
+}
+
+// userAudience marks user tokens so they can't be passed off as participant tokens
+const userAudience = "user"
+
+// userTokenTTL is how long a sign in lasts before the identity provider is asked again
+const userTokenTTL = 7 * 24 * time.Hour
+
+// ErrInvalidToken is returned for tokens that are malformed, expired or of the wrong kind
+var ErrInvalidToken = errors.New("invalid token")
+
 type Client struct {
 	jwtSecret string
 }
@@ -28,14 +46,18 @@ func NewClient(jwtSecret string) (*Client, error) {
 }
 
 func (c *Client) CreateAccessToken(participant types.Participant) (string, error) {
-	expirationTime := time.Now().Add(24 * time.Hour)
+	now := time.Now()
+	expirationTime := now.Add(24 * time.Hour)
 
 	claims := ParticipantClaims{
 		RoomID:  participant.RoomID,
 		Name:    participant.Name,
 		IsAdmin: participant.IsAdmin,
+		UserID:  participant.UserID,
 		StandardClaims: jwt.StandardClaims{
 			ExpiresAt: expirationTime.Unix(),
+			// Tokens issued before the participant last joined belong to a kicked namesake
+			IssuedAt: now.Unix(),
 		},
 	}
 
@@ -48,9 +70,43 @@ func (c *Client) GetClaims(tokenString string) (*ParticipantClaims, error) {
 		return []byte(c.jwtSecret), nil
 	})
 
-	if claims, ok := token.Claims.(*ParticipantClaims); ok && token.Valid {
+	if claims, ok := token.Claims.(*ParticipantClaims); ok && token.Valid && claims.Audience != userAudience {
 		return claims, nil
+	} else if err == nil {
+		return nil, ErrInvalidToken
 	} else {
 		return nil, err
 	}
 }
+
+// CreateUserToken signs in user, the token is given to HostRoom and JoinRoom to join as them
+func (c *Client) CreateUserToken(user types.User) (string, error) {
+	claims := UserClaims{
+		UserID: user.ID,
+		StandardClaims: jwt.StandardClaims{
+			Audience:  userAudience,
+			Subject:   user.ID,
+			ExpiresAt: time.Now().Add(userTokenTTL).Unix(),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(c.jwtSecret))
+}
+
+// GetUserClaims returns the claims of a token made by CreateUserToken
+func (c *Client) GetUserClaims(tokenString string) (*UserClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &UserClaims{}, func(token *jwt.Token) (interface{}, error) {
+		return []byte(c.jwtSecret), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	claims, ok := token.Claims.(*UserClaims)
+	if !ok || !token.Valid || !claims.VerifyAudience(userAudience, true) || claims.UserID == "" {
+		return nil, ErrInvalidToken
+	}
+
+	return claims, nil
+}
@@ -0,0 +1,178 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	jose "gopkg.in/square/go-jose.v2"
+	josejwt "gopkg.in/square/go-jose.v2/jwt"
+)
+
+const (
+	// jwksTTL is how long fetched signing keys are trusted before they're fetched again
+	jwksTTL = time.Hour
+	// jwksMinRefresh stops tokens with unknown key IDs from hammering the identity provider
+	jwksMinRefresh = time.Minute
+	// clockLeeway allows for clock drift between us and the identity provider
+	clockLeeway = time.Minute
+)
+
+// ErrInvalidIDToken is returned for ID tokens that fail verification
+var ErrInvalidIDToken = errors.New("invalid ID token")
+
+// Identity is who an ID token says the user is
+type Identity struct {
+	Issuer  string
+	Subject string
+	Email   string
+	Name    string
+}
+
+// UserID is the stable ID of the identity, the same for every sign in with the same account
+func (i Identity) UserID() string {
+	sum := sha256.Sum256([]byte(i.Issuer + " " + i.Subject))
+	return hex.EncodeToString(sum[:16])
+}
+
+// OIDCVerifier verifies ID tokens issued by an OpenID Connect identity provider to our client
+type OIDCVerifier struct {
+	issuer     string
+	clientID   string
+	jwksURL    string
+	httpClient *http.Client
+
+	mu        sync.Mutex
+	keys      *jose.JSONWebKeySet
+	fetchedAt time.Time
+}
+
+// NewOIDCVerifier instantiates a verifier of issuer's ID tokens for clientID, signed with the
+// keys published at jwksURL
+func NewOIDCVerifier(issuer, clientID, jwksURL string, httpClient *http.Client) *OIDCVerifier {
+	return &OIDCVerifier{
+		issuer:     issuer,
+		clientID:   clientID,
+		jwksURL:    jwksURL,
+		httpClient: httpClient,
+	}
+}
+
+// Verify checks rawIDToken's signature, issuer, audience and expiry and returns its identity
+func (v *OIDCVerifier) Verify(ctx context.Context, rawIDToken string) (*Identity, error) {
+	token, err := josejwt.ParseSigned(rawIDToken)
+	if err != nil {
+		return nil, ErrInvalidIDToken
+	}
+
+	if len(token.Headers) != 1 {
+		return nil, ErrInvalidIDToken
+	}
+
+	key, err := v.key(ctx, token.Headers[0].KeyID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only the provider's public keys are ever used, so HMAC signed tokens can't verify
+	if !key.IsPublic() || key.Use == "enc" || (key.Algorithm != "" && key.Algorithm != token.Headers[0].Algorithm) {
+		return nil, ErrInvalidIDToken
+	}
+
+	claims := josejwt.Claims{}
+	profile := struct {
+		Email         string `json:"email"`
+		EmailVerified bool   `json:"email_verified"`
+		Name          string `json:"name"`
+	}{}
+
+	err = token.Claims(key.Key, &claims, &profile)
+	if err != nil {
+		return nil, ErrInvalidIDToken
+	}
+
+	if claims.Expiry == nil || claims.Subject == "" {
+		return nil, ErrInvalidIDToken
+	}
+
+	err = claims.ValidateWithLeeway(josejwt.Expected{
+		Issuer:   v.issuer,
+		Audience: josejwt.Audience{v.clientID},
+		Time:     time.Now(),
+	}, clockLeeway)
+	if err != nil {
+		return nil, ErrInvalidIDToken
+	}
+
+	identity := &Identity{
+		Issuer:  claims.Issuer,
+		Subject: claims.Subject,
+		Name:    profile.Name,
+	}
+
+	// Unverified addresses could belong to anyone, they aren't kept
+	if profile.EmailVerified {
+		identity.Email = profile.Email
+	}
+
+	return identity, nil
+}
+
+// key returns the signing key with kid, fetching the key set when it's stale or doesn't have it
+func (v *OIDCVerifier) key(ctx context.Context, kid string) (*jose.JSONWebKey, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	now := time.Now()
+
+	if v.keys == nil || now.Sub(v.fetchedAt) > jwksTTL || (len(v.keys.Key(kid)) == 0 && now.Sub(v.fetchedAt) > jwksMinRefresh) {
+		keys, err := v.fetchKeys(ctx)
+		if err != nil {
+			// Keep verifying with the keys we have if the provider is briefly unreachable
+			if v.keys == nil {
+				return nil, err
+			}
+		} else {
+			v.keys = keys
+			v.fetchedAt = now
+		}
+	}
+
+	matches := v.keys.Key(kid)
+	if len(matches) == 0 {
+		return nil, ErrInvalidIDToken
+	}
+
+	return &matches[0], nil
+}
+
+func (v *OIDCVerifier) fetchKeys(ctx context.Context) (*jose.JSONWebKeySet, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, v.jwksURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWKS request: %v", err)
+	}
+
+	res, err := v.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch JWKS: %v", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch JWKS: status %d", res.StatusCode)
+	}
+
+	keys := &jose.JSONWebKeySet{}
+	err = json.NewDecoder(res.Body).Decode(keys)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode JWKS: %v", err)
+	}
+
+	return keys, nil
+}
@@ -31,9 +31,11 @@ func (s *Service) Authorise(ctx context.Context, request events.APIGatewayCustom
 	}
 
 	context := map[string]interface{}{
-		"IsAdmin": claims.IsAdmin,
-		"RoomID":  claims.RoomID,
-		"Name":    claims.Name,
+		"IsAdmin":  claims.IsAdmin,
+		"RoomID":   claims.RoomID,
+		"Name":     claims.Name,
+		"UserID":   claims.UserID,
+		"IssuedAt": claims.IssuedAt,
 	}
 	return generatePolicy("user", "Allow", request.MethodArn, context), nil
 }
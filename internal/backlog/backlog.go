@@ -0,0 +1,115 @@
+package backlog
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Columns of a backlog, CSV headers are matched case insensitively and other columns are ignored
+const (
+	ColumnKey         = "key"
+	ColumnTitle       = "title"
+	ColumnDescription = "description"
+	ColumnLink        = "link"
+)
+
+// ErrTooManyRows is returned when a backlog has more than the rows the caller allows
+var ErrTooManyRows = errors.New("too many rows")
+
+// Row is a story to import, Number counts the data rows from 1 so errors can point at them
+type Row struct {
+	Number      int    `json:"-"`
+	Key         string `json:"key"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Link        string `json:"link"`
+}
+
+// ParseCSV reads a CSV backlog whose first record is the header, it must have a title column
+func ParseCSV(r io.Reader, maxRows int) ([]Row, error) {
+	cr := csv.NewReader(r)
+	// Spreadsheets drop trailing empty cells, missing cells are read as empty
+	cr.FieldsPerRecord = -1
+
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("csv has no header")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read csv header: %v", err)
+	}
+
+	columns := map[string]int{}
+	for i, h := range header {
+		if i == 0 {
+			// Excel starts UTF-8 exports with a byte order mark
+			h = strings.TrimPrefix(h, "\ufeff")
+		}
+		columns[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+
+	if _, ok := columns[ColumnTitle]; !ok {
+		return nil, fmt.Errorf("csv header has no %s column", ColumnTitle)
+	}
+
+	cell := func(record []string, column string) string {
+		i, ok := columns[column]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return record[i]
+	}
+
+	rows := []Row{}
+
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read csv: %v", err)
+		}
+
+		if len(rows) == maxRows {
+			return nil, ErrTooManyRows
+		}
+
+		rows = append(rows, Row{
+			Number:      len(rows) + 1,
+			Key:         cell(record, ColumnKey),
+			Title:       cell(record, ColumnTitle),
+			Description: cell(record, ColumnDescription),
+			Link:        cell(record, ColumnLink),
+		})
+	}
+
+	return rows, nil
+}
+
+// ParseJSON reads a JSON backlog, an array of objects with the backlog's columns as fields
+func ParseJSON(r io.Reader, maxRows int) ([]Row, error) {
+	rows := []Row{}
+
+	d := json.NewDecoder(r)
+	d.DisallowUnknownFields()
+
+	err := d.Decode(&rows)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal json: %v", err)
+	}
+
+	if len(rows) > maxRows {
+		return nil, ErrTooManyRows
+	}
+
+	for i := range rows {
+		rows[i].Number = i + 1
+	}
+
+	return rows, nil
+}
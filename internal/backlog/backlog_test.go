@@ -0,0 +1,199 @@
+package backlog
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseCSV(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		maxRows int
+		want    []Row
+		wantErr error
+	}{
+		{
+			name:    "headers matched case insensitively, other columns ignored",
+			in:      " Title ,KEY,Points,Link\nLogin page,EST-1,3,https://example.com/EST-1\n",
+			maxRows: 10,
+			want:    []Row{{Number: 1, Key: "EST-1", Title: "Login page", Link: "https://example.com/EST-1"}},
+		},
+		{
+			name:    "byte order mark before the first header",
+			in:      "\ufefftitle,description\nLogin page,Let users sign in\n",
+			maxRows: 10,
+			want:    []Row{{Number: 1, Title: "Login page", Description: "Let users sign in"}},
+		},
+		{
+			name:    "blank lines are skipped and numbers stay consecutive",
+			in:      "title\nFirst\n\n\nSecond\n",
+			maxRows: 10,
+			want:    []Row{{Number: 1, Title: "First"}, {Number: 2, Title: "Second"}},
+		},
+		{
+			name:    "rows of empty cells are kept for validation to report",
+			in:      "title,key\n,\nSecond,EST-2\n",
+			maxRows: 10,
+			want:    []Row{{Number: 1}, {Number: 2, Key: "EST-2", Title: "Second"}},
+		},
+		{
+			name:    "missing trailing cells are empty",
+			in:      "key,title,description\nEST-1,Login page\n",
+			maxRows: 10,
+			want:    []Row{{Number: 1, Key: "EST-1", Title: "Login page"}},
+		},
+		{
+			name:    "header only",
+			in:      "title\n",
+			maxRows: 10,
+			want:    []Row{},
+		},
+		{
+			name:    "exactly max rows",
+			in:      "title\nOne\nTwo\n",
+			maxRows: 2,
+			want:    []Row{{Number: 1, Title: "One"}, {Number: 2, Title: "Two"}},
+		},
+		{
+			name:    "more than max rows",
+			in:      "title\nOne\nTwo\nThree\n",
+			maxRows: 2,
+			wantErr: ErrTooManyRows,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseCSV(strings.NewReader(tt.in), tt.maxRows)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseCSV: %v", err)
+			}
+
+			assertRows(t, got, tt.want)
+		})
+	}
+}
+
+func TestParseCSVInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+	}{
+		{"empty", ""},
+		{"no title column", "key,description\nEST-1,Let users sign in\n"},
+		{"unterminated quote", "title\n\"Login page\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseCSV(strings.NewReader(tt.in), 10)
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+}
+
+func TestParseJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		maxRows int
+		want    []Row
+		wantErr error
+	}{
+		{
+			name:    "rows are numbered from 1",
+			in:      `[{"key":"EST-1","title":"Login page","link":"https://example.com/EST-1"},{"title":"Logout","description":"Sign out"}]`,
+			maxRows: 10,
+			want: []Row{
+				{Number: 1, Key: "EST-1", Title: "Login page", Link: "https://example.com/EST-1"},
+				{Number: 2, Title: "Logout", Description: "Sign out"},
+			},
+		},
+		{
+			name:    "empty objects are kept for validation to report",
+			in:      `[{}]`,
+			maxRows: 10,
+			want:    []Row{{Number: 1}},
+		},
+		{
+			name:    "empty array",
+			in:      `[]`,
+			maxRows: 10,
+			want:    []Row{},
+		},
+		{
+			name:    "exactly max rows",
+			in:      `[{"title":"One"},{"title":"Two"}]`,
+			maxRows: 2,
+			want:    []Row{{Number: 1, Title: "One"}, {Number: 2, Title: "Two"}},
+		},
+		{
+			name:    "more than max rows",
+			in:      `[{"title":"One"},{"title":"Two"},{"title":"Three"}]`,
+			maxRows: 2,
+			wantErr: ErrTooManyRows,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseJSON(strings.NewReader(tt.in), tt.maxRows)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseJSON: %v", err)
+			}
+
+			assertRows(t, got, tt.want)
+		})
+	}
+}
+
+func TestParseJSONInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+	}{
+		{"empty", ""},
+		{"object instead of array", `{"title":"Login page"}`},
+		{"unknown field", `[{"title":"Login page","points":3}]`},
+		{"number is not read from the body", `[{"title":"Login page","Number":4}]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseJSON(strings.NewReader(tt.in), 10)
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+}
+
+func assertRows(t *testing.T, got, want []Row) {
+	t.Helper()
+
+	if len(got) != len(want) {
+		t.Fatalf("got %d rows, want %d: %+v", len(got), len(want), got)
+	}
+
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("row %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package delivery
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/jponc/estimatex-serverless/api/schema"
+	"github.com/jponc/estimatex-serverless/internal/repository/ddbrepository"
+	"github.com/jponc/estimatex-serverless/internal/types"
+	"github.com/jponc/estimatex-serverless/internal/webhooks"
+	"github.com/jponc/estimatex-serverless/pkg/logger"
+	"github.com/jponc/estimatex-serverless/pkg/metrics"
+)
+
+// deliveryConsumer identifies the delivery handler when deduplicating events
+const deliveryConsumer = "DeliverWebhooks"
+
+// maxAttempts is how many times an event is sent to a webhook before the delivery is given up
+const maxAttempts = 3
+
+// retryBackoff is the wait before the first retry, doubled on every retry
+const retryBackoff = time.Second
+
+const (
+	metricWebhookDelivered      = "WebhookDelivered"
+	metricWebhookDeliveryFailed = "WebhookDeliveryFailures"
+)
+
+type Service struct {
+	ddbrepository *ddbrepository.Repository
+	httpClient    *http.Client
+	metrics       *metrics.Recorder
+	router        *webhooks.Router
+}
+
+// NewService instantiates a service delivering every room event to the room's webhooks
+func NewService(ddbrepository *ddbrepository.Repository, httpClient *http.Client, metrics *metrics.Recorder) *Service {
+	s := &Service{
+		ddbrepository: ddbrepository,
+		httpClient:    httpClient,
+		metrics:       metrics,
+		router:        webhooks.NewRouter(deliveryConsumer, ddbrepository),
+	}
+
+	for _, eventType := range schema.EventTypes {
+		s.router.Register(eventType, s.deliver)
+	}
+
+	return s
+}
+
+// DeliverWebhooks routes room events from SNS to the delivery handler
+func (s *Service) DeliverWebhooks(ctx context.Context, snsEvent events.SNSEvent) error {
+	return s.router.Route(ctx, snsEvent)
+}
+
+// deliver sends event to every subscribed webhook of the room in parallel. Failed deliveries are
+// logged rather than returned so one broken endpoint doesn't redeliver to the others.
+func (s *Service) deliver(ctx context.Context, event schema.Event) error {
+	hooks, err := s.ddbrepository.FindWebhooks(ctx, event.RoomID)
+	if err != nil {
+		return fmt.Errorf("failed to get webhooks: %v", err)
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %v", err)
+	}
+
+	var wg sync.WaitGroup
+
+	for _, hook := range hooks {
+		if !subscribed(hook.Webhook, event.Type) {
+			continue
+		}
+
+		wg.Add(1)
+		go func(hook ddbrepository.StoredWebhook) {
+			defer wg.Done()
+			s.deliverTo(ctx, hook, event, body)
+		}(hook)
+	}
+
+	wg.Wait()
+	return nil
+}
+
+// deliverTo sends body to hook, retrying with backoff, and records the outcome in the delivery log
+func (s *Service) deliverTo(ctx context.Context, hook ddbrepository.StoredWebhook, event schema.Event, body []byte) {
+	l := logger.FromContext(ctx).WithField(logger.FieldWebhookID, hook.ID)
+
+	id, err := newDeliveryID()
+	if err != nil {
+		l.Errorf("failed to generate delivery ID: %v", err)
+		return
+	}
+
+	d := types.WebhookDelivery{
+		ID:        id,
+		WebhookID: hook.ID,
+		EventID:   event.ID,
+		EventType: event.Type,
+	}
+
+	start := time.Now()
+	backoff := retryBackoff
+
+	for {
+		d.Attempts++
+
+		var retry bool
+		d.StatusCode, retry, err = s.send(ctx, hook, d.ID, event.Type, body)
+		if err == nil {
+			d.Succeeded = true
+			d.Error = ""
+			break
+		}
+
+		d.Error = err.Error()
+		if !retry || d.Attempts == maxAttempts || !sleep(ctx, backoff) {
+			break
+		}
+		backoff *= 2
+	}
+
+	d.DeliveredAt = time.Now()
+	d.DurationMs = d.DeliveredAt.Sub(start).Milliseconds()
+
+	if d.Succeeded {
+		s.metrics.Emit(ctx, metrics.Count(metricWebhookDelivered, 1))
+		l.Infof("delivered event to webhook in %d attempts", d.Attempts)
+	} else {
+		s.metrics.Emit(ctx, metrics.Count(metricWebhookDeliveryFailed, 1))
+		l.Warnf("failed to deliver event to webhook: %s", d.Error)
+	}
+
+	err = s.ddbrepository.SaveWebhookDelivery(ctx, event.RoomID, d)
+	if err != nil {
+		l.Errorf("failed to save webhook delivery: %v", err)
+	}
+}
+
+// send makes a single delivery attempt, retry reports whether a failure may succeed later
+func (s *Service) send(ctx context.Context, hook ddbrepository.StoredWebhook, deliveryID, eventType string, body []byte) (int, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, false, fmt.Errorf("invalid webhook request: %v", err)
+	}
+
+	now := time.Now()
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "EstimateX-Webhooks/1")
+	req.Header.Set(SignatureHeader, Sign(hook.Secret, now, body))
+	req.Header.Set(TimestampHeader, fmt.Sprintf("%d", now.Unix()))
+	req.Header.Set(EventTypeHeader, eventType)
+	req.Header.Set(DeliveryHeader, deliveryID)
+
+	res, err := s.httpClient.Do(req)
+	if err != nil {
+		return 0, true, err
+	}
+	res.Body.Close()
+
+	if res.StatusCode >= 200 && res.StatusCode <= 299 {
+		return res.StatusCode, false, nil
+	}
+
+	// Client errors other than rate limiting won't change on retry
+	retry := res.StatusCode == http.StatusTooManyRequests || res.StatusCode >= 500
+	return res.StatusCode, retry, fmt.Errorf("webhook responded %d", res.StatusCode)
+}
+
+// subscribed reports whether webhook wants events of eventType
+func subscribed(webhook types.Webhook, eventType string) bool {
+	if len(webhook.EventTypes) == 0 {
+		return true
+	}
+
+	for _, t := range webhook.EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// sleep waits for d, returning false if ctx is done first
+func sleep(ctx context.Context, d time.Duration) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case <-time.After(d):
+		return true
+	}
+}
+
+func newDeliveryID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
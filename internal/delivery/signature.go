@@ -0,0 +1,33 @@
+package delivery
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Headers sent with every delivery
+const (
+	// SignatureHeader is "t=<unix timestamp>,v1=<hex HMAC-SHA256 of "<timestamp>.<body>">", keyed
+	// with the webhook's secret. Receivers should recompute it and reject old timestamps.
+	SignatureHeader = "X-EstimateX-Signature"
+	TimestampHeader = "X-EstimateX-Timestamp"
+	EventTypeHeader = "X-EstimateX-Event"
+	// DeliveryHeader stays the same across retries so receivers can drop duplicates
+	DeliveryHeader = "X-EstimateX-Delivery"
+)
+
+// Sign returns the SignatureHeader value for body sent at timestamp
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	t := strconv.FormatInt(timestamp.Unix(), 10)
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(t))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return fmt.Sprintf("t=%s,v1=%s", t, hex.EncodeToString(mac.Sum(nil)))
+}
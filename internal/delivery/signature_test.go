@@ -0,0 +1,63 @@
+package delivery
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSign(t *testing.T) {
+	at := time.Unix(1614592800, 0)
+	body := []byte(`{"type":"RoundRevealed"}`)
+
+	tests := []struct {
+		name      string
+		secret    string
+		timestamp time.Time
+		body      []byte
+		want      string
+	}{
+		{
+			name:      "signs timestamp and body",
+			secret:    "whsec_test",
+			timestamp: at,
+			body:      body,
+			want:      "t=1614592800,v1=a539f83879ef0abcc76695de6257425e1b784a1e3deaf525a7a25f07e1e5a4bc",
+		},
+		{
+			name:      "keyed with the secret",
+			secret:    "other",
+			timestamp: at,
+			body:      body,
+			want:      "t=1614592800,v1=70ef479c7581ceb619a72b1e26899685303ecd662436ab6f0bbda085c3f48d93",
+		},
+		{
+			name:      "a replay with another timestamp has another signature",
+			secret:    "whsec_test",
+			timestamp: at.Add(time.Second),
+			body:      body,
+			want:      "t=1614592801,v1=6a2d21ba799015431efdfbfa686efc76ea065d182a4f87b748bb522cf6bc65d4",
+		},
+		{
+			name:      "sub-second time is dropped",
+			secret:    "whsec_test",
+			timestamp: at.Add(900 * time.Millisecond),
+			body:      body,
+			want:      "t=1614592800,v1=a539f83879ef0abcc76695de6257425e1b784a1e3deaf525a7a25f07e1e5a4bc",
+		},
+		{
+			name:      "empty body",
+			secret:    "whsec_test",
+			timestamp: at,
+			body:      nil,
+			want:      "t=1614592800,v1=c19d1b8e3b214d5137fe2e612fd119b51550e713b71ff36994f45ea1e5a87049",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Sign(tt.secret, tt.timestamp, tt.body); got != tt.want {
+				t.Errorf("Sign = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
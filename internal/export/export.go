@@ -0,0 +1,150 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jponc/estimatex-serverless/internal/repository/ddbrepository"
+	"github.com/jponc/estimatex-serverless/internal/types"
+)
+
+// Formats an export can be written in
+const (
+	FormatJSON = "json"
+	FormatCSV  = "csv"
+)
+
+// Session is a room's results, every revealed round with its story, votes and final estimate
+type Session struct {
+	RoomID     string         `json:"room_id"`
+	CreatedAt  time.Time      `json:"created_at"`
+	ExportedAt time.Time      `json:"exported_at"`
+	Rounds     []SessionRound `json:"rounds"`
+}
+
+type SessionRound struct {
+	types.Round
+	// Story is nil for rounds voted without a story
+	Story *types.Story `json:"story,omitempty"`
+}
+
+// NewSession builds the session from a room partition
+func NewSession(p *ddbrepository.RoomPartition, exportedAt time.Time) *Session {
+	s := &Session{
+		RoomID:     p.Room.ID,
+		CreatedAt:  p.Room.CreatedAt,
+		ExportedAt: exportedAt,
+		Rounds:     []SessionRound{},
+	}
+
+	for _, r := range p.Rounds {
+		round := SessionRound{Round: r}
+		if story, ok := p.Stories[r.StoryID]; ok {
+			round.Story = &story
+		}
+
+		s.Rounds = append(s.Rounds, round)
+	}
+
+	return s
+}
+
+// csvHeader are the columns of a CSV export, one row per vote
+var csvHeader = []string{
+	"round", "parent_round", "story_key", "story_title", "story_url",
+	"participant", "vote", "comment", "changes",
+	"vote_count", "average", "min", "max", "final_estimate", "revealed_at",
+}
+
+// WriteJSON writes the session as indented JSON
+func WriteJSON(w io.Writer, s *Session) error {
+	e := json.NewEncoder(w)
+	e.SetIndent("", "  ")
+
+	err := e.Encode(s)
+	if err != nil {
+		return fmt.Errorf("failed to encode session: %v", err)
+	}
+
+	return nil
+}
+
+// WriteCSV writes one row per vote so the export pastes straight into a spreadsheet, rounds
+// nobody voted in get a single row with the round's details
+func WriteCSV(w io.Writer, s *Session) error {
+	cw := csv.NewWriter(w)
+
+	err := cw.Write(csvHeader)
+	if err != nil {
+		return fmt.Errorf("failed to write csv header: %v", err)
+	}
+
+	for _, r := range s.Rounds {
+		votes := r.Stats.Participants
+		if len(votes) == 0 {
+			votes = []types.ParticipantStats{{}}
+		}
+
+		for _, v := range votes {
+			err = cw.Write(csvRow(r, v))
+			if err != nil {
+				return fmt.Errorf("failed to write csv row: %v", err)
+			}
+		}
+	}
+
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("failed to flush csv: %v", err)
+	}
+
+	return nil
+}
+
+func csvRow(r SessionRound, v types.ParticipantStats) []string {
+	storyKey, storyTitle, storyURL := "", "", ""
+	if r.Story != nil {
+		storyKey, storyTitle, storyURL = r.Story.ExternalKey, r.Story.Title, r.Story.URL
+	}
+
+	parentRound := ""
+	if r.ParentRound > 0 {
+		parentRound = strconv.Itoa(r.ParentRound)
+	}
+
+	return []string{
+		strconv.Itoa(r.Number),
+		parentRound,
+		csvText(storyKey),
+		csvText(storyTitle),
+		csvText(storyURL),
+		csvText(v.Name),
+		csvText(v.Vote),
+		csvText(v.Comment),
+		strconv.Itoa(v.Changes),
+		strconv.Itoa(r.Stats.VoteCount),
+		formatNumber(r.Stats.Average),
+		formatNumber(r.Stats.Min),
+		formatNumber(r.Stats.Max),
+		csvText(r.FinalEstimate),
+		r.RevealedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+// csvText quotes text users entered so spreadsheets show it rather than run it as a formula
+func csvText(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+
+	return s
+}
+
+func formatNumber(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
@@ -0,0 +1,99 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+	"time"
+
+	"github.com/jponc/estimatex-serverless/internal/types"
+)
+
+func TestCSVText(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"empty", "", ""},
+		{"plain text", "Login page", "Login page"},
+		{"equals", "=HYPERLINK(\"http://evil\")", "'=HYPERLINK(\"http://evil\")"},
+		{"plus", "+1", "'+1"},
+		{"minus", "-2+3", "'-2+3"},
+		{"at", "@SUM(A1)", "'@SUM(A1)"},
+		{"tab", "\t=1", "'\t=1"},
+		{"carriage return", "\r=1", "'\r=1"},
+		{"formula character later on", "a=b", "a=b"},
+		{"already quoted", "'=1", "'=1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := csvText(tt.in); got != tt.want {
+				t.Errorf("csvText(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	revealedAt := time.Date(2021, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	s := &Session{
+		Rounds: []SessionRound{
+			{
+				Round: types.Round{
+					Number:        2,
+					ParentRound:   1,
+					RevealedAt:    revealedAt,
+					FinalEstimate: "5",
+					Stats: types.RoundStats{
+						VoteCount: 1,
+						Average:   5,
+						Min:       5,
+						Max:       5,
+						Participants: []types.ParticipantStats{
+							{Name: "=Ana", Vote: "5", Comment: "@risky", Changes: 1},
+						},
+					},
+				},
+				Story: &types.Story{ExternalKey: "EST-1", Title: "-1 day", URL: "https://example.com/EST-1"},
+			},
+			{
+				Round: types.Round{Number: 3, RevealedAt: revealedAt},
+			},
+		},
+	}
+
+	var b bytes.Buffer
+	if err := WriteCSV(&b, s); err != nil {
+		t.Fatalf("WriteCSV: %v", err)
+	}
+
+	records, err := csv.NewReader(&b).ReadAll()
+	if err != nil {
+		t.Fatalf("read csv: %v", err)
+	}
+
+	want := [][]string{
+		csvHeader,
+		{"2", "1", "EST-1", "'-1 day", "https://example.com/EST-1", "'=Ana", "5", "'@risky", "1", "1", "5", "5", "5", "5", "2021-03-01T10:00:00Z"},
+		// Rounds nobody voted in still get a row
+		{"3", "", "", "", "", "", "", "", "0", "0", "0", "0", "0", "", "2021-03-01T10:00:00Z"},
+	}
+
+	if len(records) != len(want) {
+		t.Fatalf("got %d records, want %d", len(records), len(want))
+	}
+
+	for i := range want {
+		if len(records[i]) != len(want[i]) {
+			t.Fatalf("record %d has %d columns, want %d", i, len(records[i]), len(want[i]))
+		}
+		for j := range want[i] {
+			if records[i][j] != want[i][j] {
+				t.Errorf("record %d column %s = %q, want %q", i, csvHeader[j], records[i][j], want[i][j])
+			}
+		}
+	}
+}
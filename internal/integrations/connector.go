@@ -0,0 +1,96 @@
+package integrations
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/jponc/estimatex-serverless/internal/repository/ddbrepository"
+	"github.com/jponc/estimatex-serverless/internal/types"
+	"github.com/jponc/estimatex-serverless/pkg/ssm"
+)
+
+// ErrNotConnected is returned when the room has no tracker integration
+var ErrNotConnected = errors.New("no tracker connected")
+
+// Connector stores rooms' tracker integrations, with their credentials in Parameter Store, and
+// connects to the trackers
+type Connector struct {
+	ddbrepository *ddbrepository.Repository
+	ssmClient     *ssm.Client
+	// parameterPrefix is the Parameter Store path credentials are kept under
+	parameterPrefix string
+	httpClient      *http.Client
+}
+
+// NewConnector instantiates a connector, a nil httpClient uses the one NewTracker defaults to
+func NewConnector(ddbrepository *ddbrepository.Repository, ssmClient *ssm.Client, parameterPrefix string, httpClient *http.Client) *Connector {
+	return &Connector{
+		ddbrepository:   ddbrepository,
+		ssmClient:       ssmClient,
+		parameterPrefix: parameterPrefix,
+		httpClient:      httpClient,
+	}
+}
+
+// Save checks the integration can be built and stores it for the room, replacing any previous one
+func (c *Connector) Save(ctx context.Context, roomID string, integration types.TrackerIntegration, credentials Credentials) (*types.TrackerIntegration, error) {
+	_, err := NewTracker(integration, credentials, c.httpClient)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := json.Marshal(credentials)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal credentials: %v", err)
+	}
+
+	// Credentials go first so a stored integration always has them
+	err = c.ssmClient.PutSecret(ctx, c.credentialsParameter(roomID), string(b))
+	if err != nil {
+		return nil, fmt.Errorf("failed to save credentials: %v", err)
+	}
+
+	integration.UpdatedAt = time.Now()
+
+	err = c.ddbrepository.SaveTrackerIntegration(ctx, roomID, integration)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save tracker integration: %v", err)
+	}
+
+	return &integration, nil
+}
+
+// Connect returns the room's tracker, ErrNotConnected when it has none
+func (c *Connector) Connect(ctx context.Context, roomID string) (Tracker, error) {
+	integration, err := c.ddbrepository.FindTrackerIntegration(ctx, roomID)
+	if errors.Is(err, ddbrepository.ErrNotFound) {
+		return nil, ErrNotConnected
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tracker integration: %v", err)
+	}
+
+	secret, err := c.ssmClient.GetSecret(ctx, c.credentialsParameter(roomID))
+	if errors.Is(err, ssm.ErrNotFound) {
+		return nil, ErrNotConnected
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get credentials: %v", err)
+	}
+
+	credentials := Credentials{}
+	err = json.Unmarshal([]byte(secret), &credentials)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal credentials: %v", err)
+	}
+
+	return NewTracker(*integration, credentials, c.httpClient)
+}
+
+func (c *Connector) credentialsParameter(roomID string) string {
+	return fmt.Sprintf("%s/rooms/%s/tracker", c.parameterPrefix, roomID)
+}
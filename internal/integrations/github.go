@@ -0,0 +1,131 @@
+package integrations
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/jponc/estimatex-serverless/internal/types"
+)
+
+const defaultGitHubBaseURL = "https://api.github.com"
+
+// gitHubEstimateLabelPrefix prefixes the label holding an issue's estimate, GitHub issues have no
+// story points field
+const gitHubEstimateLabelPrefix = "estimate: "
+
+// GitHubTracker talks to the GitHub REST API with a personal access or app token
+type GitHubTracker struct {
+	api  *apiClient
+	repo string
+}
+
+// NewGitHubTracker instantiates a tracker for the owner/name repo, an empty baseURL uses GitHub's
+// public API and GitHub Enterprise sites pass their own
+func NewGitHubTracker(baseURL, repo string, credentials Credentials, httpClient *http.Client) (*GitHubTracker, error) {
+	if baseURL == "" {
+		baseURL = defaultGitHubBaseURL
+	}
+
+	if strings.Count(repo, "/") != 1 {
+		return nil, fmt.Errorf("%w: github project must be owner/name, got %q", ErrInvalidIntegration, repo)
+	}
+
+	t := &GitHubTracker{
+		api: &apiClient{
+			baseURL:    strings.TrimSuffix(baseURL, "/"),
+			httpClient: httpClient,
+			authorize: func(req *http.Request) {
+				req.Header.Set("Authorization", "Bearer "+credentials.Token)
+				req.Header.Set("Accept", "application/vnd.github+json")
+			},
+		},
+		repo: repo,
+	}
+
+	return t, nil
+}
+
+type gitHubSearchResponse struct {
+	Items []struct {
+		Number  int    `json:"number"`
+		Title   string `json:"title"`
+		Body    string `json:"body"`
+		HTMLURL string `json:"html_url"`
+	} `json:"items"`
+}
+
+type gitHubLabel struct {
+	Name string `json:"name"`
+}
+
+// FindIssues runs query with GitHub's search syntax over the repo's open and closed issues, keys are
+// returned as owner/name#number
+func (t *GitHubTracker) FindIssues(ctx context.Context, query string) ([]types.TrackerIssue, error) {
+	params := url.Values{}
+	params.Set("q", strings.TrimSpace(fmt.Sprintf("repo:%s is:issue %s", t.repo, query)))
+	params.Set("per_page", strconv.Itoa(maxIssues))
+
+	res := &gitHubSearchResponse{}
+	err := t.api.do(ctx, http.MethodGet, "/search/issues?"+params.Encode(), nil, res)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search github issues: %v", err)
+	}
+
+	issues := []types.TrackerIssue{}
+	for _, i := range res.Items {
+		issues = append(issues, types.TrackerIssue{
+			Key:         fmt.Sprintf("%s#%d", t.repo, i.Number),
+			Title:       i.Title,
+			Description: i.Body,
+			URL:         i.HTMLURL,
+		})
+	}
+
+	return issues, nil
+}
+
+// SetStoryPoints replaces the issue's estimate label, key is the issue number optionally prefixed
+// with # or owner/name#
+func (t *GitHubTracker) SetStoryPoints(ctx context.Context, key, estimate string) error {
+	number, err := strconv.Atoi(key[strings.LastIndex(key, "#")+1:])
+	if err != nil {
+		return fmt.Errorf("invalid github issue key %q", key)
+	}
+
+	labelsPath := fmt.Sprintf("/repos/%s/issues/%d/labels", t.repo, number)
+
+	labels := []gitHubLabel{}
+	err = t.api.do(ctx, http.MethodGet, labelsPath, nil, &labels)
+	if err != nil {
+		return fmt.Errorf("failed to get github issue labels: %v", err)
+	}
+
+	label := gitHubEstimateLabelPrefix + estimate
+
+	for _, l := range labels {
+		if l.Name == label {
+			return nil
+		}
+
+		if strings.HasPrefix(l.Name, gitHubEstimateLabelPrefix) {
+			err = t.api.do(ctx, http.MethodDelete, labelsPath+"/"+url.PathEscape(l.Name), nil, nil)
+			if err != nil {
+				return fmt.Errorf("failed to remove github issue label: %v", err)
+			}
+		}
+	}
+
+	// Labels that don't exist yet are created in the repo
+	body := map[string][]string{"labels": {label}}
+
+	err = t.api.do(ctx, http.MethodPost, labelsPath, body, nil)
+	if err != nil {
+		return fmt.Errorf("failed to add github issue label: %v", err)
+	}
+
+	return nil
+}
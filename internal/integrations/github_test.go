@@ -0,0 +1,149 @@
+package integrations
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func newTestGitHubTracker(t *testing.T, handler http.HandlerFunc) *GitHubTracker {
+	t.Helper()
+
+	srv := httptest.NewTLSServer(handler)
+	t.Cleanup(srv.Close)
+
+	tracker, err := NewGitHubTracker(srv.URL, "acme/app", Credentials{Token: "secret"}, srv.Client())
+	if err != nil {
+		t.Fatalf("NewGitHubTracker: %v", err)
+	}
+
+	return tracker
+}
+
+func TestGitHubFindIssues(t *testing.T) {
+	tracker := newTestGitHubTracker(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/search/issues" {
+			t.Errorf("path = %s, want /search/issues", r.URL.Path)
+		}
+		if got, want := r.URL.Query().Get("q"), "repo:acme/app is:issue label:bug"; got != want {
+			t.Errorf("q = %q, want %q", got, want)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer secret" {
+			t.Errorf("authorization = %q", got)
+		}
+
+		w.Write([]byte(`{"items":[{"number":7,"title":"Crash","body":"On start","html_url":"https://github.com/acme/app/issues/7"}]}`))
+	})
+
+	issues, err := tracker.FindIssues(context.Background(), "label:bug")
+	if err != nil {
+		t.Fatalf("FindIssues: %v", err)
+	}
+
+	if len(issues) != 1 {
+		t.Fatalf("got %d issues, want 1", len(issues))
+	}
+	if issues[0].Key != "acme/app#7" || issues[0].Title != "Crash" || issues[0].URL != "https://github.com/acme/app/issues/7" {
+		t.Errorf("issue = %+v", issues[0])
+	}
+}
+
+func TestGitHubFindIssuesErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+	}{
+		{"unauthorized", http.StatusUnauthorized, `{"message":"Bad credentials"}`},
+		{"invalid query", http.StatusUnprocessableEntity, `{"message":"Validation Failed"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := newTestGitHubTracker(t, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			})
+
+			_, err := tracker.FindIssues(context.Background(), "is:")
+			if err == nil {
+				t.Fatal("FindIssues succeeded, want an error")
+			}
+			if !strings.Contains(err.Error(), tt.body) {
+				t.Errorf("error %q doesn't include the response body", err)
+			}
+		})
+	}
+}
+
+func TestGitHubSetStoryPoints(t *testing.T) {
+	requests := []string{}
+	var added []string
+
+	tracker := newTestGitHubTracker(t, func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.EscapedPath())
+
+		switch r.Method {
+		case http.MethodGet:
+			w.Write([]byte(`[{"name":"bug"},{"name":"estimate: 3"}]`))
+		case http.MethodPost:
+			body := struct {
+				Labels []string `json:"labels"`
+			}{}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				t.Errorf("decode body: %v", err)
+			}
+			added = body.Labels
+			w.Write([]byte(`[]`))
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	})
+
+	err := tracker.SetStoryPoints(context.Background(), "acme/app#7", "5")
+	if err != nil {
+		t.Fatalf("SetStoryPoints: %v", err)
+	}
+
+	// The previous estimate label is replaced, other labels are left alone
+	want := []string{
+		"GET /repos/acme/app/issues/7/labels",
+		"DELETE /repos/acme/app/issues/7/labels/estimate:%203",
+		"POST /repos/acme/app/issues/7/labels",
+	}
+	if !reflect.DeepEqual(requests, want) {
+		t.Errorf("requests = %v, want %v", requests, want)
+	}
+	if !reflect.DeepEqual(added, []string{"estimate: 5"}) {
+		t.Errorf("added labels = %v, want [estimate: 5]", added)
+	}
+}
+
+func TestGitHubSetStoryPointsErrors(t *testing.T) {
+	t.Run("invalid key", func(t *testing.T) {
+		tracker := newTestGitHubTracker(t, func(w http.ResponseWriter, r *http.Request) {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		})
+
+		err := tracker.SetStoryPoints(context.Background(), "acme/app#seven", "5")
+		if err == nil {
+			t.Error("SetStoryPoints succeeded, want an error")
+		}
+	})
+
+	t.Run("unauthorized", func(t *testing.T) {
+		tracker := newTestGitHubTracker(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"message":"Bad credentials"}`))
+		})
+
+		err := tracker.SetStoryPoints(context.Background(), "7", "5")
+		if err == nil || !strings.Contains(err.Error(), "401") {
+			t.Errorf("err = %v, want the 401 reported", err)
+		}
+	})
+}
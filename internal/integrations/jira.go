@@ -0,0 +1,113 @@
+package integrations
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/jponc/estimatex-serverless/internal/types"
+)
+
+// defaultJiraPointsField is the story points field of Jira Cloud's software projects
+const defaultJiraPointsField = "customfield_10016"
+
+// JiraTracker talks to the Jira REST API v2 with an account email and API token
+type JiraTracker struct {
+	api         *apiClient
+	project     string
+	pointsField string
+}
+
+// NewJiraTracker instantiates a tracker for project on the Jira site at baseURL
+func NewJiraTracker(baseURL, project, pointsField string, credentials Credentials, httpClient *http.Client) (*JiraTracker, error) {
+	if baseURL == "" {
+		return nil, fmt.Errorf("%w: jira needs a base url", ErrInvalidIntegration)
+	}
+
+	if credentials.Username == "" {
+		return nil, fmt.Errorf("%w: jira needs the account email as username", ErrInvalidIntegration)
+	}
+
+	if pointsField == "" {
+		pointsField = defaultJiraPointsField
+	}
+
+	t := &JiraTracker{
+		api: &apiClient{
+			baseURL:    strings.TrimSuffix(baseURL, "/"),
+			httpClient: httpClient,
+			authorize: func(req *http.Request) {
+				req.SetBasicAuth(credentials.Username, credentials.Token)
+			},
+		},
+		project:     project,
+		pointsField: pointsField,
+	}
+
+	return t, nil
+}
+
+type jiraSearchResponse struct {
+	Issues []struct {
+		Key    string `json:"key"`
+		Fields struct {
+			Summary     string `json:"summary"`
+			Description string `json:"description"`
+		} `json:"fields"`
+	} `json:"issues"`
+}
+
+// FindIssues runs query as JQL scoped to the project
+func (t *JiraTracker) FindIssues(ctx context.Context, query string) ([]types.TrackerIssue, error) {
+	jql := fmt.Sprintf("project = %q", t.project)
+	if query != "" {
+		jql = fmt.Sprintf("%s AND (%s)", jql, query)
+	}
+
+	params := url.Values{}
+	params.Set("jql", jql)
+	params.Set("fields", "summary,description")
+	params.Set("maxResults", strconv.Itoa(maxIssues))
+
+	res := &jiraSearchResponse{}
+	err := t.api.do(ctx, http.MethodGet, "/rest/api/2/search?"+params.Encode(), nil, res)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search jira issues: %v", err)
+	}
+
+	issues := []types.TrackerIssue{}
+	for _, i := range res.Issues {
+		issues = append(issues, types.TrackerIssue{
+			Key:         i.Key,
+			Title:       i.Fields.Summary,
+			Description: i.Fields.Description,
+			URL:         fmt.Sprintf("%s/browse/%s", t.api.baseURL, i.Key),
+		})
+	}
+
+	return issues, nil
+}
+
+// SetStoryPoints sets the points field, which is numeric so only numeric estimates are supported
+func (t *JiraTracker) SetStoryPoints(ctx context.Context, key, estimate string) error {
+	points, err := strconv.ParseFloat(estimate, 64)
+	if err != nil {
+		return ErrUnsupportedEstimate
+	}
+
+	body := map[string]interface{}{
+		"fields": map[string]interface{}{
+			t.pointsField: points,
+		},
+	}
+
+	err = t.api.do(ctx, http.MethodPut, "/rest/api/2/issue/"+url.PathEscape(key), body, nil)
+	if err != nil {
+		return fmt.Errorf("failed to update jira issue: %v", err)
+	}
+
+	return nil
+}
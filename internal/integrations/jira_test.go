@@ -0,0 +1,138 @@
+package integrations
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newTestJiraTracker(t *testing.T, handler http.HandlerFunc) *JiraTracker {
+	t.Helper()
+
+	srv := httptest.NewTLSServer(handler)
+	t.Cleanup(srv.Close)
+
+	tracker, err := NewJiraTracker(srv.URL, "EST", "", Credentials{Username: "dev@acme.test", Token: "secret"}, srv.Client())
+	if err != nil {
+		t.Fatalf("NewJiraTracker: %v", err)
+	}
+
+	return tracker
+}
+
+func TestJiraFindIssues(t *testing.T) {
+	tracker := newTestJiraTracker(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/rest/api/2/search" {
+			t.Errorf("path = %s, want /rest/api/2/search", r.URL.Path)
+		}
+		if got, want := r.URL.Query().Get("jql"), `project = "EST" AND (status = Open)`; got != want {
+			t.Errorf("jql = %q, want %q", got, want)
+		}
+		if user, pass, _ := r.BasicAuth(); user != "dev@acme.test" || pass != "secret" {
+			t.Errorf("basic auth = %s:%s", user, pass)
+		}
+
+		w.Write([]byte(`{"issues":[{"key":"EST-1","fields":{"summary":"Login","description":"As a user"}}]}`))
+	})
+
+	issues, err := tracker.FindIssues(context.Background(), "status = Open")
+	if err != nil {
+		t.Fatalf("FindIssues: %v", err)
+	}
+
+	if len(issues) != 1 {
+		t.Fatalf("got %d issues, want 1", len(issues))
+	}
+	if issues[0].Key != "EST-1" || issues[0].Title != "Login" || issues[0].Description != "As a user" {
+		t.Errorf("issue = %+v", issues[0])
+	}
+	if !strings.HasSuffix(issues[0].URL, "/browse/EST-1") {
+		t.Errorf("url = %s, want a /browse/EST-1 link", issues[0].URL)
+	}
+}
+
+func TestJiraFindIssuesErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+	}{
+		{"unauthorized", http.StatusUnauthorized, `{"errorMessages":["Client must be authenticated"]}`},
+		{"bad jql", http.StatusBadRequest, `{"errorMessages":["Error in the JQL Query"]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := newTestJiraTracker(t, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			})
+
+			_, err := tracker.FindIssues(context.Background(), "status = ")
+			if err == nil {
+				t.Fatal("FindIssues succeeded, want an error")
+			}
+			// The tracker's explanation is kept for the logs
+			if !strings.Contains(err.Error(), tt.body) {
+				t.Errorf("error %q doesn't include the response body", err)
+			}
+		})
+	}
+}
+
+func TestJiraSetStoryPoints(t *testing.T) {
+	var fields map[string]interface{}
+
+	tracker := newTestJiraTracker(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut || r.URL.Path != "/rest/api/2/issue/EST-1" {
+			t.Errorf("request = %s %s, want PUT /rest/api/2/issue/EST-1", r.Method, r.URL.Path)
+		}
+
+		body := struct {
+			Fields map[string]interface{} `json:"fields"`
+		}{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decode body: %v", err)
+		}
+		fields = body.Fields
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	err := tracker.SetStoryPoints(context.Background(), "EST-1", "5")
+	if err != nil {
+		t.Fatalf("SetStoryPoints: %v", err)
+	}
+
+	if fields[defaultJiraPointsField] != 5.0 {
+		t.Errorf("fields = %v, want %s set to 5", fields, defaultJiraPointsField)
+	}
+}
+
+func TestJiraSetStoryPointsErrors(t *testing.T) {
+	t.Run("non numeric estimate", func(t *testing.T) {
+		tracker := newTestJiraTracker(t, func(w http.ResponseWriter, r *http.Request) {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		})
+
+		err := tracker.SetStoryPoints(context.Background(), "EST-1", "XL")
+		if !errors.Is(err, ErrUnsupportedEstimate) {
+			t.Errorf("err = %v, want ErrUnsupportedEstimate", err)
+		}
+	})
+
+	t.Run("unauthorized", func(t *testing.T) {
+		tracker := newTestJiraTracker(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+		})
+
+		err := tracker.SetStoryPoints(context.Background(), "EST-1", "3")
+		if err == nil || !strings.Contains(err.Error(), "401") {
+			t.Errorf("err = %v, want the 401 reported", err)
+		}
+	})
+}
@@ -0,0 +1,93 @@
+package integrations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/jponc/estimatex-serverless/api/schema"
+	"github.com/jponc/estimatex-serverless/internal/repository/ddbrepository"
+	"github.com/jponc/estimatex-serverless/internal/webhooks"
+	"github.com/jponc/estimatex-serverless/pkg/logger"
+)
+
+// writeBackConsumer identifies the write back handler when deduplicating events
+const writeBackConsumer = "WriteBackEstimate"
+
+type Service struct {
+	ddbrepository *ddbrepository.Repository
+	connector     *Connector
+	router        *webhooks.Router
+}
+
+// NewService instantiates a service writing finalized estimates back to the rooms' trackers
+func NewService(ddbrepository *ddbrepository.Repository, connector *Connector) *Service {
+	s := &Service{
+		ddbrepository: ddbrepository,
+		connector:     connector,
+		router:        webhooks.NewRouter(writeBackConsumer, ddbrepository),
+	}
+
+	s.router.Register(schema.EstimateFinalized, s.writeBackEstimate)
+
+	return s
+}
+
+// WriteBackEstimate routes room events from SNS to the write back handler
+func (s *Service) WriteBackEstimate(ctx context.Context, snsEvent events.SNSEvent) error {
+	return s.router.Route(ctx, snsEvent)
+}
+
+// writeBackEstimate sets the estimate on the tracker issue the round's story was imported from.
+// Rounds without a tracker issue, and rooms without a tracker, are skipped.
+func (s *Service) writeBackEstimate(ctx context.Context, event schema.Event) error {
+	var msg schema.EstimateFinalizedMessage
+	err := event.UnmarshalPayload(&msg)
+	if err != nil {
+		return fmt.Errorf("unable to unmarshal payload: %v", err)
+	}
+
+	l := logger.FromContext(ctx)
+
+	round, err := s.ddbrepository.FindRound(ctx, event.RoomID, msg.Round)
+	if err != nil {
+		return fmt.Errorf("failed to get round: %v", err)
+	}
+
+	if round.StoryID == "" {
+		l.Debug("round has no story, skipping write back")
+		return nil
+	}
+
+	story, err := s.ddbrepository.FindStory(ctx, event.RoomID, round.StoryID)
+	if err != nil {
+		return fmt.Errorf("failed to get story: %v", err)
+	}
+
+	if story.ExternalKey == "" {
+		l.Debug("story has no tracker issue, skipping write back")
+		return nil
+	}
+
+	tracker, err := s.connector.Connect(ctx, event.RoomID)
+	if errors.Is(err, ErrNotConnected) {
+		l.Debug("room has no tracker, skipping write back")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to connect to tracker: %v", err)
+	}
+
+	err = tracker.SetStoryPoints(ctx, story.ExternalKey, msg.Estimate)
+	if errors.Is(err, ErrUnsupportedEstimate) {
+		l.Warnf("tracker can't store estimate %q, skipping write back", msg.Estimate)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write back estimate: %v", err)
+	}
+
+	l.Infof("wrote estimate back to %s", story.ExternalKey)
+	return nil
+}
@@ -0,0 +1,116 @@
+package integrations
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/jponc/estimatex-serverless/internal/delivery"
+	"github.com/jponc/estimatex-serverless/internal/types"
+)
+
+// maxIssues limits how many issues a search returns
+const maxIssues = 50
+
+// ErrInvalidIntegration is returned when an integration is missing settings its tracker needs
+var ErrInvalidIntegration = errors.New("invalid tracker integration")
+
+// ErrUnsupportedEstimate is returned when the tracker can't store the estimate, e.g. a t-shirt
+// size in a numeric story points field
+var ErrUnsupportedEstimate = errors.New("estimate not supported by tracker")
+
+// Tracker is an issue tracker stories are imported from and estimates are written back to
+type Tracker interface {
+	// FindIssues searches the integration's project with a query in the tracker's own syntax
+	FindIssues(ctx context.Context, query string) ([]types.TrackerIssue, error)
+	// SetStoryPoints records estimate on the issue with key
+	SetStoryPoints(ctx context.Context, key, estimate string) error
+}
+
+// Credentials authenticate with a tracker, Username is the Jira account email and unused by GitHub
+type Credentials struct {
+	Username string `json:"username,omitempty"`
+	Token    string `json:"token"`
+}
+
+// NewTracker instantiates the tracker for integration. The base URL is set by room admins, so a nil
+// httpClient uses delivery's, which only connects to public addresses.
+func NewTracker(integration types.TrackerIntegration, credentials Credentials, httpClient *http.Client) (Tracker, error) {
+	if httpClient == nil {
+		httpClient = delivery.NewHTTPClient()
+	}
+
+	// Credentials are sent with every request, they never go over plain http
+	if integration.BaseURL != "" && !strings.HasPrefix(integration.BaseURL, "https://") {
+		return nil, fmt.Errorf("%w: base url must be https", ErrInvalidIntegration)
+	}
+
+	switch integration.Kind {
+	case types.TrackerJira:
+		return NewJiraTracker(integration.BaseURL, integration.Project, integration.PointsField, credentials, httpClient)
+	case types.TrackerGitHub:
+		return NewGitHubTracker(integration.BaseURL, integration.Project, credentials, httpClient)
+	default:
+		return nil, fmt.Errorf("%w: unknown tracker %q", ErrInvalidIntegration, integration.Kind)
+	}
+}
+
+// apiClient sends JSON requests to a tracker's REST API
+type apiClient struct {
+	baseURL    string
+	httpClient *http.Client
+	authorize  func(req *http.Request)
+}
+
+// do sends body as JSON and decodes the response into out when it isn't nil, non 2xx responses
+// are returned as errors
+func (c *apiClient) do(ctx context.Context, method, path string, body, out interface{}) error {
+	var reqBody io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %v", err)
+		}
+		reqBody = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reqBody)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %v", err)
+	}
+
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	c.authorize(req)
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send %s %s: %v", method, path, err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		// Trackers explain rejected requests in the body, keep the start of it for the logs
+		b, _ := ioutil.ReadAll(io.LimitReader(res.Body, 512))
+		return fmt.Errorf("%s %s returned %d: %s", method, path, res.StatusCode, b)
+	}
+
+	if out == nil {
+		return nil
+	}
+
+	err = json.NewDecoder(res.Body).Decode(out)
+	if err != nil {
+		return fmt.Errorf("failed to decode %s %s response: %v", method, path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,21 @@
+package integrations
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jponc/estimatex-serverless/internal/types"
+)
+
+func TestNewTrackerRequiresHTTPS(t *testing.T) {
+	integration := types.TrackerIntegration{
+		Kind:    types.TrackerJira,
+		BaseURL: "http://acme.atlassian.net",
+		Project: "EST",
+	}
+
+	_, err := NewTracker(integration, Credentials{Username: "dev@acme.test", Token: "secret"}, nil)
+	if !errors.Is(err, ErrInvalidIntegration) {
+		t.Errorf("err = %v, want ErrInvalidIntegration", err)
+	}
+}
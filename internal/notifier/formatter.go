@@ -0,0 +1,166 @@
+package notifier
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/jponc/estimatex-serverless/internal/types"
+)
+
+// Summary is a round result to post, either its reveal or its final estimate
+type Summary struct {
+	RoomID string
+	Round  types.Round
+	// Story is nil for rounds voted without a story
+	Story *types.Story
+	// Finalized is true once the round's estimate has been agreed
+	Finalized bool
+}
+
+// Formatter renders a summary as the JSON body of a chat app's incoming webhook
+type Formatter interface {
+	Format(summary Summary) (interface{}, error)
+}
+
+// DefaultFormatters are the formatters for every supported chat app, keyed by types.ChatIntegration.Kind
+func DefaultFormatters() map[string]Formatter {
+	return map[string]Formatter{
+		types.ChatSlack: SlackFormatter{},
+		types.ChatTeams: TeamsFormatter{},
+	}
+}
+
+// SlackFormatter posts Block Kit messages with a plain text fallback for notifications
+type SlackFormatter struct{}
+
+func (SlackFormatter) Format(summary Summary) (interface{}, error) {
+	section := func(text string) map[string]interface{} {
+		return map[string]interface{}{
+			"type": "section",
+			"text": map[string]string{"type": "mrkdwn", "text": text},
+		}
+	}
+
+	blocks := []interface{}{
+		section(fmt.Sprintf("*%s*", slackEscape(title(summary)))),
+	}
+
+	if summary.Story != nil {
+		story := slackEscape(summary.Story.Title)
+		if summary.Story.URL != "" {
+			story = fmt.Sprintf("<%s|%s>", summary.Story.URL, story)
+		}
+		blocks = append(blocks, section(story))
+	}
+
+	blocks = append(blocks,
+		section(slackEscape(strings.Join(votes(summary.Round.Stats, "• "), "\n"))),
+		map[string]interface{}{
+			"type": "context",
+			"elements": []interface{}{
+				map[string]string{"type": "mrkdwn", "text": stats(summary.Round)},
+			},
+		},
+	)
+
+	return map[string]interface{}{
+		"text":   title(summary),
+		"blocks": blocks,
+	}, nil
+}
+
+// slackEscape escapes the characters Slack reads as control sequences in names, titles and comments
+func slackEscape(text string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+}
+
+// TeamsFormatter posts legacy MessageCards, the format Teams' incoming webhook connectors accept
+type TeamsFormatter struct{}
+
+func (TeamsFormatter) Format(summary Summary) (interface{}, error) {
+	card := map[string]interface{}{
+		"@type":      "MessageCard",
+		"@context":   "https://schema.org/extensions",
+		"summary":    title(summary),
+		"themeColor": "0076D7",
+		"title":      title(summary),
+	}
+
+	section := map[string]interface{}{
+		"text": strings.Join(votes(summary.Round.Stats, "- "), "\n\n"),
+		"facts": []map[string]string{
+			{"name": "Stats", "value": stats(summary.Round)},
+		},
+	}
+
+	if summary.Story != nil {
+		section["activityTitle"] = summary.Story.Title
+
+		if summary.Story.URL != "" {
+			card["potentialAction"] = []interface{}{
+				map[string]interface{}{
+					"@type":   "OpenUri",
+					"name":    "Open story",
+					"targets": []map[string]string{{"os": "default", "uri": summary.Story.URL}},
+				},
+			}
+		}
+	}
+
+	card["sections"] = []interface{}{section}
+
+	return card, nil
+}
+
+func title(summary Summary) string {
+	if summary.Finalized {
+		estimate := summary.Round.FinalEstimate
+		if summary.Round.FinalEstimateOverride {
+			estimate += " (override)"
+		}
+		return fmt.Sprintf("Room %s round %d estimated at %s", summary.RoomID, summary.Round.Number, estimate)
+	}
+
+	return fmt.Sprintf("Room %s round %d votes revealed", summary.RoomID, summary.Round.Number)
+}
+
+// votes lists every participant's vote and comment, one per line
+func votes(s types.RoundStats, bullet string) []string {
+	if len(s.Participants) == 0 {
+		return []string{"No votes"}
+	}
+
+	lines := []string{}
+	for _, p := range s.Participants {
+		line := fmt.Sprintf("%s%s: %s", bullet, p.Name, p.Vote)
+		if p.Comment != "" {
+			line += fmt.Sprintf(" (%s)", p.Comment)
+		}
+		lines = append(lines, line)
+	}
+
+	return lines
+}
+
+func stats(r types.Round) string {
+	s := fmt.Sprintf("%d votes", r.Stats.VoteCount)
+
+	if r.Stats.NumericVoteCount > 0 {
+		s += fmt.Sprintf(" · average %s · min %s · max %s",
+			formatNumber(r.Stats.Average), formatNumber(r.Stats.Min), formatNumber(r.Stats.Max))
+	}
+
+	if r.Comparison != nil {
+		s += fmt.Sprintf(" · spread %s → %s since round %d",
+			formatNumber(r.Comparison.PreviousSpread), formatNumber(r.Comparison.Spread), r.Comparison.ParentRound)
+	}
+
+	return s
+}
+
+// formatNumber rounds f to one decimal place for display
+func formatNumber(f float64) string {
+	return strconv.FormatFloat(math.Round(f*10)/10, 'f', -1, 64)
+}
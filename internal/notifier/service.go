@@ -0,0 +1,144 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/jponc/estimatex-serverless/api/schema"
+	"github.com/jponc/estimatex-serverless/internal/repository/ddbrepository"
+	"github.com/jponc/estimatex-serverless/internal/webhooks"
+	"github.com/jponc/estimatex-serverless/pkg/logger"
+)
+
+// notifyConsumer identifies the chat handlers when deduplicating events
+const notifyConsumer = "NotifyChat"
+
+type Service struct {
+	ddbrepository *ddbrepository.Repository
+	httpClient    *http.Client
+	formatters    map[string]Formatter
+	router        *webhooks.Router
+}
+
+// NewService instantiates a service posting round results to the rooms' chat integrations with
+// the formatter registered for their kind
+func NewService(ddbrepository *ddbrepository.Repository, httpClient *http.Client, formatters map[string]Formatter) *Service {
+	s := &Service{
+		ddbrepository: ddbrepository,
+		httpClient:    httpClient,
+		formatters:    formatters,
+		router:        webhooks.NewRouter(notifyConsumer, ddbrepository),
+	}
+
+	s.router.Register(schema.RevealVotes, s.notifyRevealVotes)
+	s.router.Register(schema.EstimateFinalized, s.notifyEstimateFinalized)
+
+	return s
+}
+
+// NotifyChat routes room events from SNS to the chat handlers
+func (s *Service) NotifyChat(ctx context.Context, snsEvent events.SNSEvent) error {
+	return s.router.Route(ctx, snsEvent)
+}
+
+func (s *Service) notifyRevealVotes(ctx context.Context, event schema.Event) error {
+	var msg schema.RevealVotesMessage
+	err := event.UnmarshalPayload(&msg)
+	if err != nil {
+		return fmt.Errorf("unable to unmarshal payload: %v", err)
+	}
+
+	return s.notify(ctx, event.RoomID, msg.Round, false)
+}
+
+func (s *Service) notifyEstimateFinalized(ctx context.Context, event schema.Event) error {
+	var msg schema.EstimateFinalizedMessage
+	err := event.UnmarshalPayload(&msg)
+	if err != nil {
+		return fmt.Errorf("unable to unmarshal payload: %v", err)
+	}
+
+	return s.notify(ctx, event.RoomID, msg.Round, true)
+}
+
+// notify posts the round's summary to the room's chat, rooms without a chat integration are skipped
+func (s *Service) notify(ctx context.Context, roomID string, roundNumber int, finalized bool) error {
+	integration, err := s.ddbrepository.FindChatIntegration(ctx, roomID)
+	if errors.Is(err, ddbrepository.ErrNotFound) {
+		logger.FromContext(ctx).Debug("room has no chat integration, skipping notification")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get chat integration: %v", err)
+	}
+
+	formatter, ok := s.formatters[integration.Kind]
+	if !ok {
+		return fmt.Errorf("no formatter for chat %s", integration.Kind)
+	}
+
+	round, err := s.ddbrepository.FindRound(ctx, roomID, roundNumber)
+	if err != nil {
+		return fmt.Errorf("failed to get round: %v", err)
+	}
+
+	summary := Summary{
+		RoomID:    roomID,
+		Round:     *round,
+		Finalized: finalized,
+	}
+
+	if round.StoryID != "" {
+		summary.Story, err = s.ddbrepository.FindStory(ctx, roomID, round.StoryID)
+		if err != nil && !errors.Is(err, ddbrepository.ErrNotFound) {
+			return fmt.Errorf("failed to get story: %v", err)
+		}
+	}
+
+	message, err := formatter.Format(summary)
+	if err != nil {
+		return fmt.Errorf("failed to format %s message: %v", integration.Kind, err)
+	}
+
+	err = s.post(ctx, integration.WebhookURL, message)
+	if err != nil {
+		return fmt.Errorf("failed to post to %s: %v", integration.Kind, err)
+	}
+
+	logger.FromContext(ctx).Infof("posted round %d to %s", roundNumber, integration.Kind)
+	return nil
+}
+
+// post sends message as JSON to the incoming webhook url
+func (s *Service) post(ctx context.Context, url string, message interface{}) error {
+	body, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %v", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		b, _ := ioutil.ReadAll(io.LimitReader(res.Body, 512))
+		return fmt.Errorf("responded %d: %s", res.StatusCode, b)
+	}
+
+	return nil
+}
@@ -0,0 +1,199 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jponc/estimatex-serverless/internal/types"
+)
+
+func testSummary() Summary {
+	return Summary{
+		RoomID: "abc123",
+		Round: types.Round{
+			Number:        2,
+			FinalEstimate: "5",
+			Stats: types.RoundStats{
+				VoteCount:        2,
+				NumericVoteCount: 2,
+				Average:          4,
+				Min:              3,
+				Max:              5,
+				Participants: []types.ParticipantStats{
+					{Name: "Ana", Vote: "3", Comment: "<b>reuse</b> the form"},
+					{Name: "Ben", Vote: "5"},
+				},
+			},
+		},
+		Story: &types.Story{
+			Title: "Login & signup",
+			URL:   "https://tracker.test/EST-1",
+		},
+		Finalized: true,
+	}
+}
+
+// postToTestServer posts what formatter renders for summary through Service.post and returns the
+// body the webhook received
+func postToTestServer(t *testing.T, formatter Formatter, summary Summary) map[string]interface{} {
+	t.Helper()
+
+	var received map[string]interface{}
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("method = %s, want POST", r.Method)
+		}
+		if got := r.Header.Get("Content-Type"); got != "application/json" {
+			t.Errorf("content type = %q, want application/json", got)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Errorf("decode body: %v", err)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	message, err := formatter.Format(summary)
+	if err != nil {
+		t.Fatalf("Format: %v", err)
+	}
+
+	s := &Service{httpClient: srv.Client()}
+	if err := s.post(context.Background(), srv.URL, message); err != nil {
+		t.Fatalf("post: %v", err)
+	}
+
+	return received
+}
+
+func TestSlackFormatter(t *testing.T) {
+	body := postToTestServer(t, SlackFormatter{}, testSummary())
+
+	if got, want := body["text"], "Room abc123 round 2 estimated at 5"; got != want {
+		t.Errorf("text = %q, want %q", got, want)
+	}
+
+	raw, _ := json.Marshal(body["blocks"])
+	var blocks []struct {
+		Type string `json:"type"`
+		Text struct {
+			Text string `json:"text"`
+		} `json:"text"`
+		Elements []struct {
+			Text string `json:"text"`
+		} `json:"elements"`
+	}
+	if err := json.Unmarshal(raw, &blocks); err != nil {
+		t.Fatalf("unmarshal blocks: %v", err)
+	}
+	if len(blocks) != 4 {
+		t.Fatalf("got %d blocks, want 4", len(blocks))
+	}
+
+	if got, want := blocks[1].Text.Text, "<https://tracker.test/EST-1|Login &amp; signup>"; got != want {
+		t.Errorf("story = %q, want %q", got, want)
+	}
+	// Comments can't inject Slack markup
+	if got, want := blocks[2].Text.Text, "• Ana: 3 (&lt;b&gt;reuse&lt;/b&gt; the form)\n• Ben: 5"; got != want {
+		t.Errorf("votes = %q, want %q", got, want)
+	}
+	if blocks[3].Type != "context" || len(blocks[3].Elements) != 1 {
+		t.Fatalf("stats block = %+v, want a context block", blocks[3])
+	}
+	if got, want := blocks[3].Elements[0].Text, "2 votes · average 4 · min 3 · max 5"; got != want {
+		t.Errorf("stats = %q, want %q", got, want)
+	}
+}
+
+func TestSlackFormatterWithoutStory(t *testing.T) {
+	summary := testSummary()
+	summary.Story = nil
+	summary.Finalized = false
+	summary.Round.Stats = types.RoundStats{}
+
+	body := postToTestServer(t, SlackFormatter{}, summary)
+
+	if got, want := body["text"], "Room abc123 round 2 votes revealed"; got != want {
+		t.Errorf("text = %q, want %q", got, want)
+	}
+	if blocks := body["blocks"].([]interface{}); len(blocks) != 3 {
+		t.Errorf("got %d blocks, want 3 without a story", len(blocks))
+	}
+}
+
+func TestTeamsFormatter(t *testing.T) {
+	summary := testSummary()
+	summary.Round.FinalEstimateOverride = true
+
+	body := postToTestServer(t, TeamsFormatter{}, summary)
+
+	if body["@type"] != "MessageCard" {
+		t.Errorf("@type = %v, want MessageCard", body["@type"])
+	}
+	if got, want := body["title"], "Room abc123 round 2 estimated at 5 (override)"; got != want {
+		t.Errorf("title = %q, want %q", got, want)
+	}
+
+	raw, _ := json.Marshal(body)
+	var card struct {
+		Sections []struct {
+			ActivityTitle string              `json:"activityTitle"`
+			Text          string              `json:"text"`
+			Facts         []map[string]string `json:"facts"`
+		} `json:"sections"`
+		PotentialAction []struct {
+			Targets []map[string]string `json:"targets"`
+		} `json:"potentialAction"`
+	}
+	if err := json.Unmarshal(raw, &card); err != nil {
+		t.Fatalf("unmarshal card: %v", err)
+	}
+
+	if len(card.Sections) != 1 {
+		t.Fatalf("got %d sections, want 1", len(card.Sections))
+	}
+	section := card.Sections[0]
+	if section.ActivityTitle != "Login & signup" {
+		t.Errorf("activity title = %q, want the story title", section.ActivityTitle)
+	}
+	if got, want := section.Text, "- Ana: 3 (<b>reuse</b> the form)\n\n- Ben: 5"; got != want {
+		t.Errorf("text = %q, want %q", got, want)
+	}
+	if len(section.Facts) != 1 || section.Facts[0]["value"] != "2 votes · average 4 · min 3 · max 5" {
+		t.Errorf("facts = %v", section.Facts)
+	}
+
+	if len(card.PotentialAction) != 1 || card.PotentialAction[0].Targets[0]["uri"] != "https://tracker.test/EST-1" {
+		t.Errorf("potential action = %+v, want a link to the story", card.PotentialAction)
+	}
+}
+
+func TestPostFailure(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ioutil.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte("no_service"))
+	}))
+	defer srv.Close()
+
+	s := &Service{httpClient: srv.Client()}
+
+	err := s.post(context.Background(), srv.URL, map[string]string{"text": "hi"})
+	if err == nil {
+		t.Fatal("post succeeded, want an error")
+	}
+	// The chat's explanation is kept for the logs
+	if !strings.Contains(err.Error(), "404") || !strings.Contains(err.Error(), "no_service") {
+		t.Errorf("err = %v, want the status and body reported", err)
+	}
+
+	// A webhook that can't be reached is an error too
+	srv.Close()
+	if err := s.post(context.Background(), srv.URL, map[string]string{"text": "hi"}); err == nil {
+		t.Error("post to a closed server succeeded, want an error")
+	}
+}
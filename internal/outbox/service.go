@@ -0,0 +1,76 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/jponc/estimatex-serverless/api/schema"
+	"github.com/jponc/estimatex-serverless/internal/repository/ddbrepository"
+	"github.com/jponc/estimatex-serverless/pkg/logger"
+	"github.com/jponc/estimatex-serverless/pkg/sns"
+	log "github.com/sirupsen/logrus"
+)
+
+type Service struct {
+	snsClient *sns.Client
+}
+
+// NewService instantiates a new service
+func NewService(snsClient *sns.Client) *Service {
+	return &Service{
+		snsClient: snsClient,
+	}
+}
+
+// Relay publishes every event appended to a room event log to the room events topic. Returning an
+// error retries the whole batch, consumers drop the redelivered events by ID.
+func (s *Service) Relay(ctx context.Context, ddbEvent events.DynamoDBEvent) error {
+	if s.snsClient == nil {
+		return fmt.Errorf("snsClient not defined")
+	}
+
+	for _, record := range ddbEvent.Records {
+		if record.EventName != string(events.DynamoDBOperationTypeInsert) {
+			continue
+		}
+
+		sk, ok := record.Change.NewImage["SK"]
+		if !ok || sk.DataType() != events.DataTypeString || !strings.HasPrefix(sk.String(), ddbrepository.EventPrefix) {
+			continue
+		}
+
+		e, ok := record.Change.NewImage[ddbrepository.EventAttribute]
+		if !ok || e.DataType() != events.DataTypeString {
+			return fmt.Errorf("event item %s has no event", sk.String())
+		}
+
+		var event schema.Event
+		err := json.Unmarshal([]byte(e.String()), &event)
+		if err != nil {
+			return fmt.Errorf("unable to unmarshal event: %v", err)
+		}
+
+		eventCtx := logger.WithCorrelationID(ctx, event.CorrelationID)
+		eventCtx = logger.WithFields(eventCtx, log.Fields{
+			logger.FieldRoomID:    event.RoomID,
+			logger.FieldEventID:   event.ID,
+			logger.FieldEventType: event.Type,
+		})
+
+		attributes := map[string]string{
+			schema.CorrelationIDAttribute: event.CorrelationID,
+		}
+
+		err = s.snsClient.PublishWithAttributes(eventCtx, schema.RoomEventsTopic, event, attributes)
+		if err != nil {
+			return fmt.Errorf("failed to publish %s event (%s): %v", event.Type, event.ID, err)
+		}
+
+		logger.FromContext(eventCtx).Info("relayed event")
+	}
+
+	return nil
+}
@@ -0,0 +1,256 @@
+package projector
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/jponc/estimatex-serverless/api/schema"
+	"github.com/jponc/estimatex-serverless/internal/types"
+)
+
+// RoomState is the room state rebuilt from its event log
+type RoomState struct {
+	RoomID         string                        `json:"room_id"`
+	Sequence       int64                         `json:"sequence"`
+	RoundStartedAt time.Time                     `json:"round_started_at"`
+	Round          int                           `json:"round"`
+	ParentRound    int                           `json:"parent_round,omitempty"`
+	CurrentStoryID string                        `json:"current_story_id,omitempty"`
+	StoryQueue     []string                      `json:"story_queue"`
+	Revealed       bool                          `json:"revealed"`
+	Participants   map[string]*types.Participant `json:"participants"`
+}
+
+// NewRoomState instantiates an empty room state
+func NewRoomState(roomID string) *RoomState {
+	return &RoomState{
+		RoomID:       roomID,
+		StoryQueue:   []string{},
+		Participants: map[string]*types.Participant{},
+	}
+}
+
+// Project rebuilds the room state by applying events in sequence order
+func Project(roomID string, events []schema.Event) (*RoomState, error) {
+	sorted := make([]schema.Event, len(events))
+	copy(sorted, events)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Sequence < sorted[j].Sequence
+	})
+
+	s := NewRoomState(roomID)
+	for _, e := range sorted {
+		err := s.Apply(e)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+// Apply applies a single event. Events at or below the current sequence are ignored since they
+// have already been applied. Sequences are gapless, an event's sequence is claimed in the
+// transaction that stores it.
+func (s *RoomState) Apply(event schema.Event) error {
+	if event.RoomID != s.RoomID {
+		return fmt.Errorf("event (%s) belongs to room %s", event.ID, event.RoomID)
+	}
+
+	if event.Sequence <= s.Sequence {
+		return nil
+	}
+
+	// The room is created with its first event
+	if s.RoundStartedAt.IsZero() {
+		s.RoundStartedAt = event.Timestamp
+		s.Round = 1
+	}
+
+	var err error
+
+	switch event.Type {
+	case schema.ParticipantJoined:
+		err = s.applyParticipantJoined(event)
+	case schema.ParticipantVoted:
+		err = s.applyParticipantVoted(event)
+	case schema.RevealVotes:
+		err = s.applyRevealVotes(event)
+	case schema.ResetVotes:
+		err = s.applyResetVotes(event)
+	case schema.ParticipantKicked:
+		err = s.applyParticipantKicked(event)
+	case schema.ParticipantRenamed:
+		err = s.applyParticipantRenamed(event)
+	case schema.StoryQueueChanged:
+		err = s.applyStoryQueueChanged(event)
+	case schema.StoryChanged:
+		err = s.applyStoryChanged(event)
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to apply %s event (%s): %v", event.Type, event.ID, err)
+	}
+
+	s.Sequence = event.Sequence
+	return nil
+}
+
+// ParticipantList returns the participants ordered by when they joined
+func (s *RoomState) ParticipantList() []types.Participant {
+	participants := []types.Participant{}
+	for _, p := range s.Participants {
+		participants = append(participants, *p)
+	}
+
+	sort.Slice(participants, func(i, j int) bool {
+		return participants[i].CreatedAt.Before(participants[j].CreatedAt)
+	})
+
+	return participants
+}
+
+func (s *RoomState) applyParticipantJoined(event schema.Event) error {
+	var msg schema.ParticipantJoinedMessage
+	err := event.UnmarshalPayload(&msg)
+	if err != nil {
+		return err
+	}
+
+	s.Participants[msg.ParticipantName] = &types.Participant{
+		RoomID:    s.RoomID,
+		Name:      msg.ParticipantName,
+		IsAdmin:   msg.IsAdmin,
+		CreatedAt: event.Timestamp,
+		UserID:    msg.UserID,
+	}
+
+	return nil
+}
+
+func (s *RoomState) applyParticipantVoted(event schema.Event) error {
+	var msg schema.ParticipantVotedMessage
+	err := event.UnmarshalPayload(&msg)
+	if err != nil {
+		return err
+	}
+
+	p, ok := s.Participants[msg.ParticipantName]
+	if !ok {
+		return fmt.Errorf("participant %s not found", msg.ParticipantName)
+	}
+
+	// Comments aren't logged with votes, the reveal restores the latest ones
+	p.RecordVote(msg.Vote, "", event.Timestamp)
+	return nil
+}
+
+func (s *RoomState) applyRevealVotes(event schema.Event) error {
+	var msg schema.RevealVotesMessage
+	err := event.UnmarshalPayload(&msg)
+	if err != nil {
+		return err
+	}
+
+	s.Revealed = true
+	for _, ps := range msg.Stats.Participants {
+		if p, ok := s.Participants[ps.Name]; ok {
+			p.Comment = ps.Comment
+		}
+	}
+
+	return nil
+}
+
+func (s *RoomState) applyResetVotes(event schema.Event) error {
+	var msg schema.ResetVotesMessage
+	err := event.UnmarshalPayload(&msg)
+	if err != nil {
+		return err
+	}
+
+	// Resets logged before rounds were numbered only move on by one
+	round := msg.Round
+	if round == 0 {
+		round = s.Round + 1
+	}
+
+	s.Revealed = false
+	s.RoundStartedAt = event.Timestamp
+	s.Round = round
+	s.ParentRound = msg.ParentRound
+	for _, p := range s.Participants {
+		p.ResetVote()
+	}
+
+	return nil
+}
+
+func (s *RoomState) applyStoryQueueChanged(event schema.Event) error {
+	var msg schema.StoryQueueChangedMessage
+	err := event.UnmarshalPayload(&msg)
+	if err != nil {
+		return err
+	}
+
+	s.StoryQueue = msg.StoryIDs
+	return nil
+}
+
+// applyStoryChanged starts a fresh round on the story, which was taken from the head of the queue
+func (s *RoomState) applyStoryChanged(event schema.Event) error {
+	var msg schema.StoryChangedMessage
+	err := event.UnmarshalPayload(&msg)
+	if err != nil {
+		return err
+	}
+
+	s.CurrentStoryID = ""
+	if msg.Story != nil {
+		s.CurrentStoryID = msg.Story.ID
+	}
+	if len(s.StoryQueue) > 0 {
+		s.StoryQueue = s.StoryQueue[1:]
+	}
+
+	s.Revealed = false
+	s.RoundStartedAt = event.Timestamp
+	s.Round = msg.Round
+	s.ParentRound = 0
+	for _, p := range s.Participants {
+		p.ResetVote()
+	}
+
+	return nil
+}
+
+func (s *RoomState) applyParticipantKicked(event schema.Event) error {
+	var msg schema.ParticipantKickedMessage
+	err := event.UnmarshalPayload(&msg)
+	if err != nil {
+		return err
+	}
+
+	delete(s.Participants, msg.ParticipantName)
+	return nil
+}
+
+func (s *RoomState) applyParticipantRenamed(event schema.Event) error {
+	var msg schema.ParticipantRenamedMessage
+	err := event.UnmarshalPayload(&msg)
+	if err != nil {
+		return err
+	}
+
+	p, ok := s.Participants[msg.ParticipantName]
+	if !ok {
+		return fmt.Errorf("participant %s not found", msg.ParticipantName)
+	}
+
+	delete(s.Participants, msg.ParticipantName)
+	p.Name = msg.NewParticipantName
+	s.Participants[p.Name] = p
+
+	return nil
+}
@@ -0,0 +1,71 @@
+package projector
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jponc/estimatex-serverless/internal/repository/ddbrepository"
+	"github.com/jponc/estimatex-serverless/internal/types"
+)
+
+// ReplayRoomRequest is the payload the ReplayRoom Lambda is invoked with
+type ReplayRoomRequest struct {
+	RoomID string `json:"room_id"`
+	// Restore writes the projected participants back, otherwise the projection is only returned
+	Restore bool `json:"restore"`
+}
+
+type ReplayRoomResponse struct {
+	Sequence     int64               `json:"sequence"`
+	Revealed     bool                `json:"revealed"`
+	Participants []types.Participant `json:"participants"`
+}
+
+type Service struct {
+	ddbrepository *ddbrepository.Repository
+}
+
+// NewService instantiates a new service
+func NewService(ddbrepository *ddbrepository.Repository) *Service {
+	return &Service{
+		ddbrepository: ddbrepository,
+	}
+}
+
+// ReplayRoom rebuilds the room state from its event log
+func (s *Service) ReplayRoom(ctx context.Context, req ReplayRoomRequest) (*ReplayRoomResponse, error) {
+	if s.ddbrepository == nil {
+		return nil, fmt.Errorf("ddbrepository not defined")
+	}
+
+	if req.RoomID == "" {
+		return nil, fmt.Errorf("room_id can't be blank")
+	}
+
+	events, _, err := s.ddbrepository.FindRoomEvents(ctx, req.RoomID, 0, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find room events: %v", err)
+	}
+
+	state, err := Project(req.RoomID, events)
+	if err != nil {
+		return nil, fmt.Errorf("failed to project room: %v", err)
+	}
+
+	participants := state.ParticipantList()
+
+	if req.Restore {
+		err = s.ddbrepository.RestoreParticipants(ctx, req.RoomID, participants)
+		if err != nil {
+			return nil, fmt.Errorf("failed to restore participants: %v", err)
+		}
+	}
+
+	res := &ReplayRoomResponse{
+		Sequence:     state.Sequence,
+		Revealed:     state.Revealed,
+		Participants: participants,
+	}
+
+	return res, nil
+}
@@ -0,0 +1,106 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jponc/estimatex-serverless/internal/repository/ddbrepository"
+)
+
+// Rule is a token bucket holding up to Burst tokens and adding one back every Every
+type Rule struct {
+	// Name namespaces the buckets so the same key can be limited by several rules
+	Name  string
+	Burst int
+	Every time.Duration
+}
+
+// refill returns bucket with the tokens added since its last refill, a missing bucket is full.
+// Time towards the next token is kept until the bucket is full again.
+func (r Rule) refill(bucket *ddbrepository.RateLimitBucket, now time.Time) ddbrepository.RateLimitBucket {
+	if bucket == nil {
+		return ddbrepository.RateLimitBucket{Tokens: r.Burst, LastRefill: now}
+	}
+
+	added := int(now.Sub(bucket.LastRefill) / r.Every)
+	if added <= 0 {
+		return *bucket
+	}
+
+	if bucket.Tokens+added >= r.Burst {
+		return ddbrepository.RateLimitBucket{Tokens: r.Burst, LastRefill: now}
+	}
+
+	return ddbrepository.RateLimitBucket{
+		Tokens:     bucket.Tokens + added,
+		LastRefill: bucket.LastRefill.Add(time.Duration(added) * r.Every),
+	}
+}
+
+// Result is the outcome of taking a token
+type Result struct {
+	Allowed bool
+	// RetryAfter is how long until a token is available again when the request isn't allowed
+	RetryAfter time.Duration
+}
+
+type Limiter struct {
+	ddbrepository *ddbrepository.Repository
+	now           func() time.Time
+}
+
+// NewLimiter instantiates a limiter storing its buckets in DynamoDB
+func NewLimiter(ddbrepository *ddbrepository.Repository) *Limiter {
+	return &Limiter{
+		ddbrepository: ddbrepository,
+		now:           time.Now,
+	}
+}
+
+// maxTakeAttempts is how many times Allow reads a bucket again after losing the race for it,
+// a room's bucket is shared by everyone voting at once
+const maxTakeAttempts = 3
+
+// Allow refills key's bucket for the time since its last refill and takes a token from it. The
+// bucket is written with a single conditional update on the state read, requests that keep losing
+// the race for it are limited.
+func (l *Limiter) Allow(ctx context.Context, rule Rule, key string) (*Result, error) {
+	if rule.Burst <= 0 || rule.Every <= 0 {
+		return nil, fmt.Errorf("invalid rate limit rule %s", rule.Name)
+	}
+
+	bucketKey := fmt.Sprintf("%s_%s", rule.Name, key)
+
+	for attempt := 0; attempt < maxTakeAttempts; attempt++ {
+		now := l.now()
+
+		read, err := l.ddbrepository.FindRateLimitBucket(ctx, bucketKey)
+		if errors.Is(err, ddbrepository.ErrNotFound) {
+			read = nil
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to get bucket: %v", err)
+		}
+
+		next := rule.refill(read, now)
+		if next.Tokens == 0 {
+			return &Result{Allowed: false, RetryAfter: next.LastRefill.Add(rule.Every).Sub(now)}, nil
+		}
+		next.Tokens--
+
+		// An untouched bucket is full again after Burst tokens' worth of time
+		expiresAt := now.Add(time.Duration(rule.Burst) * rule.Every)
+
+		ok, err := l.ddbrepository.TakeRateLimitToken(ctx, bucketKey, read, next, expiresAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to take token: %v", err)
+		}
+
+		if ok {
+			return &Result{Allowed: true}, nil
+		}
+	}
+
+	return &Result{Allowed: false, RetryAfter: rule.Every}, nil
+}
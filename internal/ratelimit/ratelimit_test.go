@@ -0,0 +1,71 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jponc/estimatex-serverless/internal/repository/ddbrepository"
+)
+
+func TestRefill(t *testing.T) {
+	rule := Rule{Name: "CastVote", Burst: 10, Every: 2 * time.Second}
+	last := time.Unix(1600000000, 0)
+
+	tests := []struct {
+		name   string
+		bucket *ddbrepository.RateLimitBucket
+		now    time.Time
+		want   ddbrepository.RateLimitBucket
+	}{
+		{
+			name: "new bucket starts full",
+			now:  last,
+			want: ddbrepository.RateLimitBucket{Tokens: 10, LastRefill: last},
+		},
+		{
+			name:   "no token before Every",
+			bucket: &ddbrepository.RateLimitBucket{Tokens: 0, LastRefill: last},
+			now:    last.Add(1900 * time.Millisecond),
+			want:   ddbrepository.RateLimitBucket{Tokens: 0, LastRefill: last},
+		},
+		{
+			name:   "one token per Every, keeping the time towards the next",
+			bucket: &ddbrepository.RateLimitBucket{Tokens: 1, LastRefill: last},
+			now:    last.Add(5 * time.Second),
+			want:   ddbrepository.RateLimitBucket{Tokens: 3, LastRefill: last.Add(4 * time.Second)},
+		},
+		{
+			name:   "never above Burst",
+			bucket: &ddbrepository.RateLimitBucket{Tokens: 8, LastRefill: last},
+			now:    last.Add(time.Hour),
+			want:   ddbrepository.RateLimitBucket{Tokens: 10, LastRefill: last.Add(time.Hour)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := rule.refill(tt.bucket, tt.now)
+
+			if got.Tokens != tt.want.Tokens || !got.LastRefill.Equal(tt.want.LastRefill) {
+				t.Errorf("refill = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// A client that spent the bucket at the end of one stretch can't spend it again right after,
+// which a fixed window would allow
+func TestRefillAfterBurst(t *testing.T) {
+	rule := Rule{Name: "CastVote", Burst: 10, Every: 2 * time.Second}
+	now := time.Unix(1600000000, 0)
+
+	bucket := rule.refill(nil, now)
+	for i := 0; i < rule.Burst; i++ {
+		bucket.Tokens--
+	}
+
+	bucket = rule.refill(&bucket, now.Add(3*time.Second))
+	if bucket.Tokens != 1 {
+		t.Errorf("tokens = %d, want 1 after 3s", bucket.Tokens)
+	}
+}
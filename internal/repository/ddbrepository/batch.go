@@ -0,0 +1,83 @@
+package ddbrepository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	awsDynamodb "github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// maxBatchWriteItems is the most items a single BatchWriteItem request accepts
+const maxBatchWriteItems = 25
+
+// maxBatchWriteAttempts is how many times a batch is sent before its unprocessed items are given up
+const maxBatchWriteAttempts = 5
+
+// batchWriteBackoff is the wait before the first retry of unprocessed items, doubled on every retry
+const batchWriteBackoff = 50 * time.Millisecond
+
+// batchPut writes items in batches of maxBatchWriteItems, retrying the items DynamoDB leaves
+// unprocessed with exponential backoff. Returns the items still unprocessed after every attempt.
+func (r *Repository) batchPut(ctx context.Context, items []map[string]*awsDynamodb.AttributeValue) ([]map[string]*awsDynamodb.AttributeValue, error) {
+	tableName := r.dynamodbClient.GetTableName()
+	failed := []map[string]*awsDynamodb.AttributeValue{}
+
+	for start := 0; start < len(items); start += maxBatchWriteItems {
+		end := start + maxBatchWriteItems
+		if end > len(items) {
+			end = len(items)
+		}
+
+		requests := []*awsDynamodb.WriteRequest{}
+		for _, item := range items[start:end] {
+			requests = append(requests, &awsDynamodb.WriteRequest{
+				PutRequest: &awsDynamodb.PutRequest{Item: item},
+			})
+		}
+
+		backoff := batchWriteBackoff
+
+		for attempt := 1; len(requests) > 0; attempt++ {
+			input := &awsDynamodb.BatchWriteItemInput{
+				RequestItems: map[string][]*awsDynamodb.WriteRequest{
+					tableName: requests,
+				},
+			}
+
+			output, err := r.dynamodbClient.BatchWriteItem(ctx, input)
+			if err != nil {
+				return nil, fmt.Errorf("failed to batch write items: %v", err)
+			}
+
+			requests = output.UnprocessedItems[tableName]
+			if len(requests) == 0 || attempt == maxBatchWriteAttempts {
+				break
+			}
+
+			select {
+			case <-ctx.Done():
+				return nil, fmt.Errorf("failed to batch write items: %v", ctx.Err())
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+		}
+
+		for _, req := range requests {
+			failed = append(failed, req.PutRequest.Item)
+		}
+	}
+
+	return failed, nil
+}
+
+// itemSK returns the SK of a marshalled item
+func itemSK(item map[string]*awsDynamodb.AttributeValue) string {
+	sk, ok := item["SK"]
+	if !ok {
+		return ""
+	}
+
+	return aws.StringValue(sk.S)
+}
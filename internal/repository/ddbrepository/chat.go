@@ -0,0 +1,110 @@
+package ddbrepository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	awsDynamodb "github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/jponc/estimatex-serverless/internal/types"
+)
+
+// chatIntegrationSK is the SK of a room's chat integration
+const chatIntegrationSK = "ChatIntegration"
+
+// StoredChatIntegration is a chat integration with the incoming webhook URL messages are posted to
+type StoredChatIntegration struct {
+	types.ChatIntegration
+	WebhookURL string
+}
+
+type chatIntegrationItem struct {
+	PK         string                `json:"PK"`
+	SK         string                `json:"SK"`
+	Data       types.ChatIntegration `json:"Data"`
+	WebhookURL string                `json:"WebhookURL"`
+}
+
+// SaveChatIntegration stores the room's chat integration, replacing any previous one
+func (r *Repository) SaveChatIntegration(ctx context.Context, roomID string, integration StoredChatIntegration) error {
+	item := chatIntegrationItem{
+		PK:         fmt.Sprintf("Room_%s", roomID),
+		SK:         chatIntegrationSK,
+		Data:       integration.ChatIntegration,
+		WebhookURL: integration.WebhookURL,
+	}
+
+	itemMap, err := dynamodbattribute.MarshalMap(item)
+	if err != nil {
+		return fmt.Errorf("failed to ddb marshal chat integration item, %v", err)
+	}
+
+	input := &awsDynamodb.PutItemInput{
+		Item:      itemMap,
+		TableName: aws.String(r.dynamodbClient.GetTableName()),
+	}
+
+	_, err = r.dynamodbClient.PutItem(ctx, input)
+	if err != nil {
+		return fmt.Errorf("failed to put chat integration: %v", err)
+	}
+
+	return nil
+}
+
+// RemoveChatIntegration deletes the room's chat integration, ErrNotFound when it has none
+func (r *Repository) RemoveChatIntegration(ctx context.Context, roomID string) error {
+	input := &awsDynamodb.DeleteItemInput{
+		Key:                 chatIntegrationKey(roomID),
+		ConditionExpression: aws.String("attribute_exists(PK)"),
+		TableName:           aws.String(r.dynamodbClient.GetTableName()),
+	}
+
+	_, err := r.dynamodbClient.DeleteItem(ctx, input)
+	if err != nil {
+		if isConditionalCheckFailed(err) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("failed to delete chat integration: %v", err)
+	}
+
+	return nil
+}
+
+// FindChatIntegration returns the room's chat integration, ErrNotFound when it has none
+func (r *Repository) FindChatIntegration(ctx context.Context, roomID string) (*StoredChatIntegration, error) {
+	i := chatIntegrationItem{}
+
+	input := &awsDynamodb.GetItemInput{
+		Key:       chatIntegrationKey(roomID),
+		TableName: aws.String(r.dynamodbClient.GetTableName()),
+	}
+
+	output, err := r.dynamodbClient.GetItem(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chat integration: %v", err)
+	}
+
+	if output.Item == nil {
+		return nil, ErrNotFound
+	}
+
+	err = dynamodbattribute.UnmarshalMap(output.Item, &i)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal map: %v", err)
+	}
+
+	return &StoredChatIntegration{ChatIntegration: i.Data, WebhookURL: i.WebhookURL}, nil
+}
+
+func chatIntegrationKey(roomID string) map[string]*awsDynamodb.AttributeValue {
+	return map[string]*awsDynamodb.AttributeValue{
+		"PK": {
+			S: aws.String(fmt.Sprintf("Room_%s", roomID)),
+		},
+		"SK": {
+			S: aws.String(chatIntegrationSK),
+		},
+	}
+}
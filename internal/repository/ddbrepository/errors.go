@@ -6,4 +6,8 @@ func (e ErrString) Error() string {
 	return string(e)
 }
 
-const ErrNotFound = ErrString("not found")
+const (
+	ErrNotFound      = ErrString("not found")
+	ErrAlreadyExists = ErrString("already exists")
+	ErrConflict      = ErrString("modified concurrently")
+)
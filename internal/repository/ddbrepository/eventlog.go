@@ -0,0 +1,377 @@
+package ddbrepository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	awsDynamodb "github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/jponc/estimatex-serverless/api/schema"
+)
+
+// EventPrefix prefixes the SK of room event log items. The log doubles as the outbox, the relay
+// publishes every stream record with this prefix.
+const EventPrefix = "Event_"
+
+// EventAttribute holds the JSON encoded event on event log items
+const EventAttribute = "Event"
+
+// PublishEvent appends event to the room's event log without any other state change
+func (r *Repository) PublishEvent(ctx context.Context, event *schema.Event) error {
+	return r.transactWithEvent(ctx, event)
+}
+
+// FindRoomEvents returns the room's events with a sequence number greater than since, oldest
+// first. At most limit events are returned, 0 returns them all, and more reports whether there
+// are later ones.
+func (r *Repository) FindRoomEvents(ctx context.Context, roomID string, since int64, limit int) (events []schema.Event, more bool, err error) {
+	items := []map[string]*awsDynamodb.AttributeValue{}
+
+	input := &awsDynamodb.QueryInput{
+		KeyConditionExpression: aws.String("PK = :PK and SK BETWEEN :from AND :to"),
+		ExpressionAttributeValues: map[string]*awsDynamodb.AttributeValue{
+			":PK": {
+				S: aws.String(fmt.Sprintf("Room_%s", roomID)),
+			},
+			":from": {
+				S: aws.String(eventSK(since + 1)),
+			},
+			":to": {
+				S: aws.String(EventPrefix + "99999999999999999999"),
+			},
+		},
+		TableName: aws.String(r.dynamodbClient.GetTableName()),
+	}
+
+	// One more than the page tells whether there's another
+	if limit > 0 {
+		input.Limit = aws.Int64(int64(limit) + 1)
+	}
+
+	for {
+		output, err := r.dynamodbClient.Query(ctx, input)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to query room events: %v", err)
+		}
+
+		items = append(items, output.Items...)
+
+		if output.LastEvaluatedKey == nil || (limit > 0 && len(items) > limit) {
+			break
+		}
+		input.ExclusiveStartKey = output.LastEvaluatedKey
+	}
+
+	if limit > 0 && len(items) > limit {
+		items = items[:limit]
+		more = true
+	}
+
+	events = []schema.Event{}
+	for _, i := range items {
+		e, ok := i[EventAttribute]
+		if !ok || e.S == nil {
+			return nil, false, fmt.Errorf("event item %s has no event", aws.StringValue(i["SK"].S))
+		}
+
+		var event schema.Event
+		err := json.Unmarshal([]byte(*e.S), &event)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to unmarshal event: %v", err)
+		}
+
+		events = append(events, event)
+	}
+
+	return events, more, nil
+}
+
+// maxSequenceAttempts is how many times transactWithEvent claims a sequence before giving up on a
+// busy room
+const maxSequenceAttempts = 8
+
+// maxTransactItems is DynamoDB's limit on the items of one transaction
+const maxTransactItems = 100
+
+// MaxRoomParticipants caps a room so resetting its votes fits a transaction, which updates every
+// participant, the room and logs the event. The margin covers participants joining concurrently.
+const MaxRoomParticipants = maxTransactItems - 10
+
+// transactWithEvent writes items together with the event's log item in a single transaction,
+// so the event is relayed if and only if the state change is stored. A nil event writes items only.
+//
+// The event's sequence is claimed in the same transaction and sequences are gapless. When another
+// event is logged first the same items are written again with the next sequence, so they must not
+// depend on room state read beforehand, writes built from a read use transactAtSequence instead.
+// ErrConflict is returned when the room stays too busy.
+func (r *Repository) transactWithEvent(ctx context.Context, event *schema.Event, items ...*awsDynamodb.TransactWriteItem) error {
+	if event == nil {
+		return r.transact(ctx, items)
+	}
+
+	for attempt := 0; attempt < maxSequenceAttempts; attempt++ {
+		if attempt > 0 {
+			time.Sleep(time.Duration(rand.Int63n(int64(attempt) * int64(20*time.Millisecond))))
+		}
+
+		prev, err := r.roomSequence(ctx, event.RoomID)
+		if err != nil {
+			return err
+		}
+
+		err = r.transactClaiming(ctx, event, prev, items)
+		if !errors.Is(err, ErrConflict) {
+			return err
+		}
+	}
+
+	return ErrConflict
+}
+
+// transactAtSequence is transactWithEvent for items built from room state read at sequence
+// readAt, they're only written if no other event was logged since. Returns ErrConflict otherwise,
+// nothing is retried since the items would overwrite what was logged in between.
+func (r *Repository) transactAtSequence(ctx context.Context, event *schema.Event, readAt int64, items ...*awsDynamodb.TransactWriteItem) error {
+	return r.transactClaiming(ctx, event, readAt, items)
+}
+
+// transactClaiming writes items with event claiming the sequence after prev. Returns ErrConflict
+// when the room moved past prev or a concurrent transaction got in the way and errConditionFailed
+// when one of the items' own conditions failed. A room update in items is moved next to the
+// event, the indexes of the items after it shift down by one.
+func (r *Repository) transactClaiming(ctx context.Context, event *schema.Event, prev int64, items []*awsDynamodb.TransactWriteItem) error {
+	event.Sequence = prev + 1
+
+	eventItem, err := r.eventPut(event)
+	if err != nil {
+		return err
+	}
+
+	claimed := append(r.claimSequence(items, event.RoomID, prev), eventItem)
+
+	err = r.transactItems(ctx, claimed)
+	if err == nil {
+		return nil
+	}
+
+	// The room update claiming the sequence comes right before the event
+	if isConditionFailedAt(err, len(claimed)-2) {
+		return ErrConflict
+	}
+
+	return transactionError(err)
+}
+
+// transact writes items in a single transaction
+func (r *Repository) transact(ctx context.Context, items []*awsDynamodb.TransactWriteItem) error {
+	err := r.transactItems(ctx, items)
+	if err != nil {
+		return transactionError(err)
+	}
+
+	return nil
+}
+
+// transactItems writes items in a single transaction, returning DynamoDB's error as it is
+func (r *Repository) transactItems(ctx context.Context, items []*awsDynamodb.TransactWriteItem) error {
+	if len(items) > maxTransactItems {
+		return fmt.Errorf("transaction has %d items, at most %d are allowed", len(items), maxTransactItems)
+	}
+
+	input := &awsDynamodb.TransactWriteItemsInput{
+		TransactItems: items,
+	}
+
+	_, err := r.dynamodbClient.TransactWriteItems(ctx, input)
+	return err
+}
+
+// roomSequence returns the sequence of the room's latest event, ErrNotFound if there's no room
+func (r *Repository) roomSequence(ctx context.Context, roomID string) (int64, error) {
+	input := &awsDynamodb.GetItemInput{
+		Key:                  roomInfoKey(roomID),
+		ConsistentRead:       aws.Bool(true),
+		ProjectionExpression: aws.String("#data.#seq"),
+		ExpressionAttributeNames: map[string]*string{
+			"#data": aws.String("Data"),
+			"#seq":  aws.String("sequence"),
+		},
+		TableName: aws.String(r.dynamodbClient.GetTableName()),
+	}
+
+	output, err := r.dynamodbClient.GetItem(ctx, input)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get room sequence: %v", err)
+	}
+
+	if output.Item == nil {
+		return 0, ErrNotFound
+	}
+
+	// Rooms that haven't logged an event yet have no sequence
+	data, ok := output.Item["Data"]
+	if !ok || data.M["sequence"] == nil || data.M["sequence"].N == nil {
+		return 0, nil
+	}
+
+	seq, err := strconv.ParseInt(*data.M["sequence"].N, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse room sequence: %v", err)
+	}
+
+	return seq, nil
+}
+
+// claimSequence returns items with the room moving from sequence prev to the next one. A
+// transaction can only touch the room item once, so an update of it in items is extended with
+// the claim, on a copy since items are reused when the claim is retried.
+func (r *Repository) claimSequence(items []*awsDynamodb.TransactWriteItem, roomID string, prev int64) []*awsDynamodb.TransactWriteItem {
+	claimed := make([]*awsDynamodb.TransactWriteItem, 0, len(items)+2)
+
+	update := &awsDynamodb.Update{
+		Key:       roomInfoKey(roomID),
+		TableName: aws.String(r.dynamodbClient.GetTableName()),
+	}
+
+	for _, item := range items {
+		if item.Update != nil && isRoomInfo(item.Update.Key, roomID) {
+			u := *item.Update
+			update = &u
+			continue
+		}
+		claimed = append(claimed, item)
+	}
+
+	names := map[string]*string{
+		"#data":     aws.String("Data"),
+		"#eventSeq": aws.String("sequence"),
+	}
+	for k, v := range update.ExpressionAttributeNames {
+		names[k] = v
+	}
+
+	values := map[string]*awsDynamodb.AttributeValue{
+		":eventSeq": {
+			N: aws.String(strconv.FormatInt(prev+1, 10)),
+		},
+		":prevEventSeq": {
+			N: aws.String(strconv.FormatInt(prev, 10)),
+		},
+	}
+	for k, v := range update.ExpressionAttributeValues {
+		values[k] = v
+	}
+
+	expr := "SET #data.#eventSeq = :eventSeq"
+	switch {
+	case update.UpdateExpression == nil:
+	case strings.HasPrefix(*update.UpdateExpression, "SET "):
+		expr += ", " + strings.TrimPrefix(*update.UpdateExpression, "SET ")
+	default:
+		expr += " " + *update.UpdateExpression
+	}
+
+	// Rooms that haven't logged an event yet have no sequence
+	cond := "attribute_exists(PK) AND (#data.#eventSeq = :prevEventSeq OR attribute_not_exists(#data.#eventSeq))"
+	if prev > 0 {
+		cond = "#data.#eventSeq = :prevEventSeq"
+	}
+	if update.ConditionExpression != nil {
+		cond = fmt.Sprintf("(%s) AND (%s)", *update.ConditionExpression, cond)
+	}
+
+	update.UpdateExpression = aws.String(expr)
+	update.ConditionExpression = aws.String(cond)
+	update.ExpressionAttributeNames = names
+	update.ExpressionAttributeValues = values
+
+	return append(claimed, &awsDynamodb.TransactWriteItem{Update: update})
+}
+
+// eventPut builds the put for the immutable event log item, sequence numbers are unique per room
+// so an existing item is never overwritten
+func (r *Repository) eventPut(event *schema.Event) (*awsDynamodb.TransactWriteItem, error) {
+	e, err := json.Marshal(event)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal event: %v", err)
+	}
+
+	return &awsDynamodb.TransactWriteItem{
+		Put: &awsDynamodb.Put{
+			Item: map[string]*awsDynamodb.AttributeValue{
+				"PK": {
+					S: aws.String(fmt.Sprintf("Room_%s", event.RoomID)),
+				},
+				"SK": {
+					S: aws.String(eventSK(event.Sequence)),
+				},
+				EventAttribute: {
+					S: aws.String(string(e)),
+				},
+			},
+			ConditionExpression: aws.String("attribute_not_exists(PK)"),
+			TableName:           aws.String(r.dynamodbClient.GetTableName()),
+		},
+	}, nil
+}
+
+// eventSK zero pads the sequence so events sort in order
+func eventSK(sequence int64) string {
+	return fmt.Sprintf("%s%020d", EventPrefix, sequence)
+}
+
+// errConditionFailed is returned by transactWithEvent when one of the item conditions failed,
+// callers translate it to ErrNotFound or ErrAlreadyExists depending on the condition they set and
+// check which item failed with isConditionFailedAt
+const errConditionFailed = ErrString("condition failed")
+
+// conditionFailedError is errConditionFailed keeping the cancellation reasons of the transaction
+type conditionFailedError struct {
+	cause *awsDynamodb.TransactionCanceledException
+}
+
+func (e conditionFailedError) Error() string {
+	return errConditionFailed.Error()
+}
+
+func (e conditionFailedError) Is(target error) bool {
+	return target == errConditionFailed
+}
+
+func (e conditionFailedError) Unwrap() error {
+	return e.cause
+}
+
+// transactionError maps the error of a canceled transaction to errConditionFailed when one of its
+// conditions failed and to ErrConflict when a concurrent transaction got in the way
+func transactionError(err error) error {
+	var terr *awsDynamodb.TransactionCanceledException
+	if !errors.As(err, &terr) {
+		return fmt.Errorf("failed to transact write items: %v", err)
+	}
+
+	for _, reason := range terr.CancellationReasons {
+		if reason.Code != nil && *reason.Code == "ConditionalCheckFailed" {
+			return conditionFailedError{cause: terr}
+		}
+	}
+
+	return ErrConflict
+}
+
+// isConditionFailedAt reports whether the transaction was canceled by the condition of its item at i
+func isConditionFailedAt(err error, i int) bool {
+	var terr *awsDynamodb.TransactionCanceledException
+	if !errors.As(err, &terr) || i < 0 || i >= len(terr.CancellationReasons) {
+		return false
+	}
+
+	code := terr.CancellationReasons[i].Code
+	return code != nil && *code == "ConditionalCheckFailed"
+}
@@ -0,0 +1,116 @@
+package ddbrepository
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	awsDynamodb "github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/jponc/estimatex-serverless/api/schema"
+	"github.com/jponc/estimatex-serverless/pkg/dynamodb"
+)
+
+func TestClaimSequence(t *testing.T) {
+	client, err := dynamodb.NewClient("us-east-1", "rooms")
+	if err != nil {
+		t.Fatalf("dynamodb.NewClient: %v", err)
+	}
+	r := &Repository{dynamodbClient: client}
+
+	t.Run("adds a room update", func(t *testing.T) {
+		items := r.claimSequence(nil, "abc", 4)
+
+		if len(items) != 1 {
+			t.Fatalf("got %d items, want 1", len(items))
+		}
+		u := items[0].Update
+		if got, want := aws.StringValue(u.UpdateExpression), "SET #data.#eventSeq = :eventSeq"; got != want {
+			t.Errorf("update = %q, want %q", got, want)
+		}
+		if got, want := aws.StringValue(u.ConditionExpression), "#data.#eventSeq = :prevEventSeq"; got != want {
+			t.Errorf("condition = %q, want %q", got, want)
+		}
+		if got := aws.StringValue(u.ExpressionAttributeValues[":eventSeq"].N); got != "5" {
+			t.Errorf(":eventSeq = %s, want 5", got)
+		}
+	})
+
+	t.Run("extends the room update in items", func(t *testing.T) {
+		queueUpdate, err := r.storyQueueUpdate("abc", []string{"s1"})
+		if err != nil {
+			t.Fatalf("storyQueueUpdate: %v", err)
+		}
+		eventPut, err := r.eventPut(&schema.Event{RoomID: "abc", Sequence: 5})
+		if err != nil {
+			t.Fatalf("eventPut: %v", err)
+		}
+
+		items := r.claimSequence([]*awsDynamodb.TransactWriteItem{queueUpdate, eventPut}, "abc", 4)
+
+		// DynamoDB rejects transactions touching an item twice
+		if len(items) != 2 {
+			t.Fatalf("got %d items, want 2", len(items))
+		}
+		u := items[1].Update
+		if got, want := aws.StringValue(u.UpdateExpression), "SET #data.#eventSeq = :eventSeq, #data.#queue = :queue"; got != want {
+			t.Errorf("update = %q, want %q", got, want)
+		}
+		if got, want := aws.StringValue(u.ConditionExpression), "(attribute_exists(PK)) AND (#data.#eventSeq = :prevEventSeq)"; got != want {
+			t.Errorf("condition = %q, want %q", got, want)
+		}
+
+		// Retries claim again from the caller's items
+		if got := aws.StringValue(queueUpdate.Update.UpdateExpression); got != "SET #data.#queue = :queue" {
+			t.Errorf("caller's update was changed to %q", got)
+		}
+	})
+
+	t.Run("first event of a room", func(t *testing.T) {
+		items := r.claimSequence(nil, "abc", 0)
+
+		want := "attribute_exists(PK) AND (#data.#eventSeq = :prevEventSeq OR attribute_not_exists(#data.#eventSeq))"
+		if got := aws.StringValue(items[0].Update.ConditionExpression); got != want {
+			t.Errorf("condition = %q, want %q", got, want)
+		}
+	})
+}
+
+func TestTransactionError(t *testing.T) {
+	canceled := func(codes ...string) error {
+		reasons := []*awsDynamodb.CancellationReason{}
+		for _, c := range codes {
+			reasons = append(reasons, &awsDynamodb.CancellationReason{Code: aws.String(c)})
+		}
+		return &awsDynamodb.TransactionCanceledException{CancellationReasons: reasons}
+	}
+
+	tests := []struct {
+		name          string
+		err           error
+		want          error
+		conditionAt   int
+		conditionFail bool
+	}{
+		{"condition failed", canceled("None", "ConditionalCheckFailed", "None"), errConditionFailed, 1, true},
+		{"conflicting transaction", canceled("TransactionConflict", "None"), ErrConflict, 0, false},
+		{"other error", errors.New("throttled"), nil, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := transactionError(tt.err)
+
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Errorf("err = %v, want %v", err, tt.want)
+			}
+			if tt.want == nil && (errors.Is(err, errConditionFailed) || errors.Is(err, ErrConflict)) {
+				t.Errorf("err = %v, want a plain error", err)
+			}
+
+			// Callers tell the failed item apart on the mapped error
+			if got := isConditionFailedAt(err, tt.conditionAt); got != tt.conditionFail {
+				t.Errorf("isConditionFailedAt(%d) = %v, want %v", tt.conditionAt, got, tt.conditionFail)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package ddbrepository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	awsDynamodb "github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/jponc/estimatex-serverless/api/schema"
+)
+
+// processedEventTTL is how long processed event markers are kept, well beyond SNS's retry window
+const processedEventTTL = 7 * 24 * time.Hour
+
+// MarkEventProcessed records that consumer has handled event, returns ErrAlreadyExists if it already has
+func (r *Repository) MarkEventProcessed(ctx context.Context, consumer string, event schema.Event) error {
+	input := &awsDynamodb.PutItemInput{
+		Item: map[string]*awsDynamodb.AttributeValue{
+			"PK": {
+				S: aws.String(fmt.Sprintf("Room_%s", event.RoomID)),
+			},
+			"SK": {
+				S: aws.String(processedEventSK(consumer, event.ID)),
+			},
+			"TTL": {
+				N: aws.String(strconv.FormatInt(time.Now().Add(processedEventTTL).Unix(), 10)),
+			},
+		},
+		ConditionExpression: aws.String("attribute_not_exists(PK)"),
+		TableName:           aws.String(r.dynamodbClient.GetTableName()),
+	}
+
+	_, err := r.dynamodbClient.PutItem(ctx, input)
+	if err != nil {
+		if isConditionalCheckFailed(err) {
+			return ErrAlreadyExists
+		}
+		return fmt.Errorf("failed to put ProcessedEvent: %v", err)
+	}
+
+	return nil
+}
+
+// UnmarkEventProcessed removes the processed marker so a failed event can be retried
+func (r *Repository) UnmarkEventProcessed(ctx context.Context, consumer string, event schema.Event) error {
+	input := &awsDynamodb.DeleteItemInput{
+		Key: map[string]*awsDynamodb.AttributeValue{
+			"PK": {
+				S: aws.String(fmt.Sprintf("Room_%s", event.RoomID)),
+			},
+			"SK": {
+				S: aws.String(processedEventSK(consumer, event.ID)),
+			},
+		},
+		TableName: aws.String(r.dynamodbClient.GetTableName()),
+	}
+
+	_, err := r.dynamodbClient.DeleteItem(ctx, input)
+	if err != nil {
+		return fmt.Errorf("failed to delete ProcessedEvent: %v", err)
+	}
+
+	return nil
+}
+
+func processedEventSK(consumer, eventID string) string {
+	return fmt.Sprintf("ProcessedEvent_%s_%s", consumer, eventID)
+}
+
+func isConditionalCheckFailed(err error) bool {
+	var aerr awserr.Error
+	return errors.As(err, &aerr) && aerr.Code() == awsDynamodb.ErrCodeConditionalCheckFailedException
+}
@@ -0,0 +1,79 @@
+package ddbrepository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	awsDynamodb "github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/jponc/estimatex-serverless/internal/types"
+)
+
+// trackerIntegrationSK is the SK of a room's tracker integration
+const trackerIntegrationSK = "TrackerIntegration"
+
+type trackerIntegrationItem struct {
+	PK   string                   `json:"PK"`
+	SK   string                   `json:"SK"`
+	Data types.TrackerIntegration `json:"Data"`
+}
+
+// SaveTrackerIntegration stores the room's tracker integration, replacing any previous one
+func (r *Repository) SaveTrackerIntegration(ctx context.Context, roomID string, integration types.TrackerIntegration) error {
+	item := trackerIntegrationItem{
+		PK:   fmt.Sprintf("Room_%s", roomID),
+		SK:   trackerIntegrationSK,
+		Data: integration,
+	}
+
+	itemMap, err := dynamodbattribute.MarshalMap(item)
+	if err != nil {
+		return fmt.Errorf("failed to ddb marshal tracker integration item, %v", err)
+	}
+
+	input := &awsDynamodb.PutItemInput{
+		Item:      itemMap,
+		TableName: aws.String(r.dynamodbClient.GetTableName()),
+	}
+
+	_, err = r.dynamodbClient.PutItem(ctx, input)
+	if err != nil {
+		return fmt.Errorf("failed to put tracker integration: %v", err)
+	}
+
+	return nil
+}
+
+// FindTrackerIntegration returns the room's tracker integration, ErrNotFound when it has none
+func (r *Repository) FindTrackerIntegration(ctx context.Context, roomID string) (*types.TrackerIntegration, error) {
+	i := trackerIntegrationItem{}
+
+	input := &awsDynamodb.GetItemInput{
+		Key: map[string]*awsDynamodb.AttributeValue{
+			"PK": {
+				S: aws.String(fmt.Sprintf("Room_%s", roomID)),
+			},
+			"SK": {
+				S: aws.String(trackerIntegrationSK),
+			},
+		},
+		TableName: aws.String(r.dynamodbClient.GetTableName()),
+	}
+
+	output, err := r.dynamodbClient.GetItem(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tracker integration: %v", err)
+	}
+
+	if output.Item == nil {
+		return nil, ErrNotFound
+	}
+
+	err = dynamodbattribute.UnmarshalMap(output.Item, &i)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal map: %v", err)
+	}
+
+	return &i.Data, nil
+}
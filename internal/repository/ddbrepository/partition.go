@@ -0,0 +1,102 @@
+package ddbrepository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	awsDynamodb "github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/jponc/estimatex-serverless/internal/types"
+)
+
+// RoomPartition is the room's results stored in its partition, the room with its revealed rounds
+// and stories
+type RoomPartition struct {
+	Room *types.Room
+	// Rounds are the revealed rounds, oldest first
+	Rounds  []types.Round
+	Stories map[string]types.Story
+}
+
+// roomPartitionPrefixes are the SK prefixes of the items a RoomPartition is loaded from, the
+// event log and participants are left out
+var roomPartitionPrefixes = []string{"RoomInfo", RoundPrefix, StoryPrefix}
+
+// FindRoomPartition loads the room's results with a paginated query per item prefix, ErrNotFound
+// when the room doesn't exist
+func (r *Repository) FindRoomPartition(ctx context.Context, roomID string) (*RoomPartition, error) {
+	p := &RoomPartition{
+		Rounds:  []types.Round{},
+		Stories: map[string]types.Story{},
+	}
+
+	for _, prefix := range roomPartitionPrefixes {
+		input := &awsDynamodb.QueryInput{
+			KeyConditionExpression: aws.String("PK = :PK and begins_with(SK, :SK)"),
+			ExpressionAttributeValues: map[string]*awsDynamodb.AttributeValue{
+				":PK": {
+					S: aws.String(fmt.Sprintf("Room_%s", roomID)),
+				},
+				":SK": {
+					S: aws.String(prefix),
+				},
+			},
+			TableName: aws.String(r.dynamodbClient.GetTableName()),
+		}
+
+		for {
+			output, err := r.dynamodbClient.Query(ctx, input)
+			if err != nil {
+				return nil, fmt.Errorf("failed to query room partition: %v", err)
+			}
+
+			for _, item := range output.Items {
+				err = p.add(item)
+				if err != nil {
+					return nil, err
+				}
+			}
+
+			if output.LastEvaluatedKey == nil {
+				break
+			}
+			input.ExclusiveStartKey = output.LastEvaluatedKey
+		}
+	}
+
+	if p.Room == nil {
+		return nil, ErrNotFound
+	}
+
+	return p, nil
+}
+
+// add unmarshals item into the entity its SK identifies, unknown items are skipped
+func (p *RoomPartition) add(item map[string]*awsDynamodb.AttributeValue) error {
+	sk := aws.StringValue(item["SK"].S)
+
+	var err error
+
+	switch {
+	case sk == "RoomInfo":
+		i := roomItem{}
+		err = dynamodbattribute.UnmarshalMap(item, &i)
+		p.Room = &i.Data
+	case strings.HasPrefix(sk, RoundPrefix):
+		i := roundItem{}
+		err = dynamodbattribute.UnmarshalMap(item, &i)
+		p.Rounds = append(p.Rounds, i.Data)
+	case strings.HasPrefix(sk, StoryPrefix):
+		i := storyItem{}
+		err = dynamodbattribute.UnmarshalMap(item, &i)
+		p.Stories[i.Data.ID] = i.Data
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal %s: %v", sk, err)
+	}
+
+	return nil
+}
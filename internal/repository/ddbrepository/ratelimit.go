@@ -0,0 +1,107 @@
+package ddbrepository
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	awsDynamodb "github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// RateLimitBucket is the state of a token bucket, LastRefill is when tokens were last added
+type RateLimitBucket struct {
+	Tokens     int
+	LastRefill time.Time
+}
+
+// FindRateLimitBucket returns the bucket stored under key, ErrNotFound when there's none
+func (r *Repository) FindRateLimitBucket(ctx context.Context, key string) (*RateLimitBucket, error) {
+	input := &awsDynamodb.GetItemInput{
+		Key:            rateLimitKey(key),
+		ConsistentRead: aws.Bool(true),
+		TableName:      aws.String(r.dynamodbClient.GetTableName()),
+	}
+
+	output, err := r.dynamodbClient.GetItem(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rate limit bucket: %v", err)
+	}
+
+	tokens, lastRefill := output.Item["Tokens"], output.Item["LastRefill"]
+	if tokens == nil || tokens.N == nil || lastRefill == nil || lastRefill.N == nil {
+		return nil, ErrNotFound
+	}
+
+	t, err := strconv.Atoi(*tokens.N)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse rate limit tokens: %v", err)
+	}
+
+	refill, err := strconv.ParseInt(*lastRefill.N, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse rate limit refill: %v", err)
+	}
+
+	return &RateLimitBucket{Tokens: t, LastRefill: time.Unix(0, refill)}, nil
+}
+
+// TakeRateLimitToken stores next as the bucket under key in a single conditional write, only if
+// the bucket is still read, nil when there was none, so concurrent Lambdas can't spend the same
+// token twice. The bucket expires at expiresAt. Returns false when another request changed the
+// bucket first.
+func (r *Repository) TakeRateLimitToken(ctx context.Context, key string, read *RateLimitBucket, next RateLimitBucket, expiresAt time.Time) (bool, error) {
+	input := &awsDynamodb.UpdateItemInput{
+		Key:              rateLimitKey(key),
+		UpdateExpression: aws.String("SET Tokens = :tokens, LastRefill = :lastRefill, #ttl = :ttl"),
+		ExpressionAttributeNames: map[string]*string{
+			"#ttl": aws.String("TTL"),
+		},
+		ExpressionAttributeValues: map[string]*awsDynamodb.AttributeValue{
+			":tokens": {
+				N: aws.String(strconv.Itoa(next.Tokens)),
+			},
+			":lastRefill": {
+				N: aws.String(strconv.FormatInt(next.LastRefill.UnixNano(), 10)),
+			},
+			":ttl": {
+				N: aws.String(strconv.FormatInt(expiresAt.Unix(), 10)),
+			},
+		},
+		TableName: aws.String(r.dynamodbClient.GetTableName()),
+	}
+
+	if read == nil {
+		input.ConditionExpression = aws.String("attribute_not_exists(Tokens)")
+	} else {
+		input.ConditionExpression = aws.String("Tokens = :readTokens AND LastRefill = :readRefill")
+		input.ExpressionAttributeValues[":readTokens"] = &awsDynamodb.AttributeValue{
+			N: aws.String(strconv.Itoa(read.Tokens)),
+		}
+		input.ExpressionAttributeValues[":readRefill"] = &awsDynamodb.AttributeValue{
+			N: aws.String(strconv.FormatInt(read.LastRefill.UnixNano(), 10)),
+		}
+	}
+
+	_, err := r.dynamodbClient.UpdateItem(ctx, input)
+	if err != nil {
+		if isConditionalCheckFailed(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to take rate limit token: %v", err)
+	}
+
+	return true, nil
+}
+
+func rateLimitKey(key string) map[string]*awsDynamodb.AttributeValue {
+	return map[string]*awsDynamodb.AttributeValue{
+		"PK": {
+			S: aws.String(fmt.Sprintf("RateLimit_%s", key)),
+		},
+		"SK": {
+			S: aws.String("RateLimit"),
+		},
+	}
+}
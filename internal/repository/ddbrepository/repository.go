@@ -4,12 +4,14 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"math/rand"
+	"strconv"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	awsDynamodb "github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/jponc/estimatex-serverless/api/schema"
+	"github.com/jponc/estimatex-serverless/internal/roomid"
 	"github.com/jponc/estimatex-serverless/internal/types"
 	"github.com/jponc/estimatex-serverless/pkg/dynamodb"
 )
@@ -39,84 +41,444 @@ func NewClient(dynamodbClient *dynamodb.Client) (*Repository, error) {
 	return r, nil
 }
 
-func (r *Repository) CreateRoom(ctx context.Context) (*types.Room, error) {
-	roomID, err := r.generateRoomID(ctx)
+// maxRoomIDAttempts is how many generated IDs CreateRoom tries before giving up
+const maxRoomIDAttempts = 5
+
+// CreateRoom stores a new room voting with deck, an empty deck means types.DefaultDeck, together
+// with its host as an admin participant and event, the host joining, as the first event of the
+// room's log. The room ID comes from roomIDs and is set on event, the room is only stored when the
+// ID is free so two rooms racing for the same ID can't overwrite each other. Rooms with a teamSlug
+// are indexed as sessions of the team and become its current session, all in the same
+// transaction. Returns ErrNotFound if there's no team with teamSlug.
+func (r *Repository) CreateRoom(ctx context.Context, roomIDs *roomid.Generator, deck []string, teamSlug, hostName, hostUserID string, event *schema.Event) (*types.Room, *types.Participant, error) {
+	for attempt := 0; attempt < maxRoomIDAttempts; attempt++ {
+		roomID, err := roomIDs.Generate()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to generate room ID: %w", err)
+		}
+
+		now := time.Now()
+
+		event.RoomID = roomID
+		event.Sequence = 1
+
+		room := &types.Room{
+			ID:             roomID,
+			CreatedAt:      now,
+			Sequence:       event.Sequence,
+			RoundStartedAt: now,
+			Round:          1,
+			Deck:           deck,
+			TeamSlug:       teamSlug,
+		}
+
+		host := &types.Participant{
+			RoomID:    roomID,
+			Name:      hostName,
+			IsAdmin:   true,
+			CreatedAt: now,
+			UserID:    hostUserID,
+		}
+
+		item := struct {
+			PK     string
+			SK     string
+			GSI1PK string `json:",omitempty"`
+			GSI1SK string `json:",omitempty"`
+			Data   *types.Room
+		}{
+			PK:   fmt.Sprintf("Room_%s", room.ID),
+			SK:   "RoomInfo",
+			Data: room,
+		}
+
+		if teamSlug != "" {
+			item.GSI1PK = teamPK(teamSlug)
+			item.GSI1SK = teamIndexSK(TeamSessionPrefix, now)
+		}
+
+		itemMap, err := dynamodbattribute.MarshalMap(item)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to ddb marshal result item record, %v", err)
+		}
+
+		hostPut, err := r.participantPut(host, "")
+		if err != nil {
+			return nil, nil, err
+		}
+
+		eventItem, err := r.eventPut(event)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		items := []*awsDynamodb.TransactWriteItem{
+			{
+				Put: &awsDynamodb.Put{
+					Item:                itemMap,
+					ConditionExpression: aws.String("attribute_not_exists(PK)"),
+					TableName:           aws.String(r.dynamodbClient.GetTableName()),
+				},
+			},
+			hostPut,
+			eventItem,
+		}
+
+		if teamSlug != "" {
+			items = append(items, &awsDynamodb.TransactWriteItem{Update: r.teamCurrentRoomUpdate(teamSlug, room.ID)})
+		}
+
+		_, err = r.dynamodbClient.TransactWriteItems(ctx, &awsDynamodb.TransactWriteItemsInput{TransactItems: items})
+		if err != nil {
+			// The room item comes first, the team update last
+			if isConditionFailedAt(err, 0) {
+				continue
+			}
+			if isConditionFailedAt(err, 3) {
+				return nil, nil, ErrNotFound
+			}
+			return nil, nil, fmt.Errorf("failed to create room: %v", err)
+		}
+
+		return room, host, nil
+	}
+
+	return nil, nil, fmt.Errorf("no free room ID after %d attempts", maxRoomIDAttempts)
+}
+
+// CastVote stores the participant's vote, keeping the one it replaces for the round, and appends
+// event to the room log in the same transaction. The vote is only stored if the participant's
+// vote hasn't changed since it was read, ErrConflict is returned otherwise and ErrNotFound if the
+// participant left.
+func (r *Repository) CastVote(ctx context.Context, participant *types.Participant, vote, comment string, event *schema.Event) error {
+	votedAt, err := dynamodbattribute.Marshal(participant.VotedAt)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate room ID (%w)", err)
+		return fmt.Errorf("failed to ddb marshal voted at, %v", err)
 	}
 
-	room := &types.Room{
-		ID:        roomID,
-		CreatedAt: time.Now(),
+	participant.RecordVote(vote, comment, event.Timestamp)
+
+	put, err := r.participantPut(participant, "attribute_exists(PK) AND #data.#votedAt = :readVotedAt")
+	if err != nil {
+		return err
+	}
+	put.Put.ExpressionAttributeNames = map[string]*string{
+		"#data":    aws.String("Data"),
+		"#votedAt": aws.String("voted_at"),
+	}
+	put.Put.ExpressionAttributeValues = map[string]*awsDynamodb.AttributeValue{
+		":readVotedAt": votedAt,
 	}
 
-	item := struct {
-		PK   string
-		SK   string
-		Data *types.Room
-	}{
-		PK:   fmt.Sprintf("Room_%s", room.ID),
-		SK:   "RoomInfo",
-		Data: room,
+	err = r.transactWithEvent(ctx, event, put)
+	if err != nil {
+		if errors.Is(err, errConditionFailed) {
+			return r.participantChanged(ctx, participant.RoomID, participant.Name)
+		}
+		if errors.Is(err, ErrConflict) {
+			return ErrConflict
+		}
+		return fmt.Errorf("failed to put Participant: %v", err)
 	}
 
-	itemMap, err := dynamodbattribute.MarshalMap(item)
+	return nil
+}
+
+// participantChanged tells why the condition on a participant read earlier failed, ErrNotFound
+// when they left and ErrConflict when they were changed in the meantime
+func (r *Repository) participantChanged(ctx context.Context, roomID, name string) error {
+	_, err := r.FindParticipant(ctx, roomID, name)
+	if errors.Is(err, ErrNotFound) {
+		return ErrNotFound
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to ddb marshal result item record, %v", err)
+		return err
 	}
 
-	input := &awsDynamodb.PutItemInput{
-		Item:      itemMap,
-		TableName: aws.String(r.dynamodbClient.GetTableName()),
+	return ErrConflict
+}
+
+// ResetVotes clears every participant's vote and history, starts round on the room and appends
+// event to the room log in the same transaction. parentRound is the round a re-vote repeats, 0
+// when the round is a fresh one. participants and the round are read from the room at sequence
+// readAt, ErrConflict is returned if it changed since.
+func (r *Repository) ResetVotes(ctx context.Context, roomID string, round, parentRound int, participants []types.Participant, readAt int64, event *schema.Event) error {
+	items, err := r.resetVotesItems(roomID, round, parentRound, participants, event.Timestamp)
+	if err != nil {
+		return err
 	}
 
-	_, err = r.dynamodbClient.PutItem(ctx, input)
+	err = r.transactAtSequence(ctx, event, readAt, items...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to put Room: %v", err)
+		// A participant left without an event, such as a restore
+		if errors.Is(err, ErrConflict) || errors.Is(err, errConditionFailed) {
+			return ErrConflict
+		}
+		return fmt.Errorf("failed to reset votes: %v", err)
 	}
 
-	return room, nil
+	return nil
 }
 
-func (r *Repository) CastVote(ctx context.Context, participant *types.Participant, vote string) error {
-	participant.LatestVote = vote
-
-	item := struct {
-		PK   string
-		SK   string
-		Data *types.Participant
-	}{
-		PK:   fmt.Sprintf("Room_%s", participant.RoomID),
-		SK:   fmt.Sprintf("Participant_%s", participant.Name),
-		Data: participant,
+// resetVotesItems builds the room update starting round and the participant updates clearing
+// their votes, the room update always comes first so callers can extend it. The room has to be on
+// the round before and the participants still in it.
+func (r *Repository) resetVotesItems(roomID string, round, parentRound int, participants []types.Participant, startedAt time.Time) ([]*awsDynamodb.TransactWriteItem, error) {
+	roundStartedAt, err := dynamodbattribute.Marshal(startedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to ddb marshal round start, %v", err)
 	}
 
-	itemMap, err := dynamodbattribute.MarshalMap(item)
+	zeroTime, err := dynamodbattribute.Marshal(time.Time{})
 	if err != nil {
-		return fmt.Errorf("failed to ddb marshal result item record, %v", err)
+		return nil, fmt.Errorf("failed to ddb marshal zero time, %v", err)
 	}
 
-	input := &awsDynamodb.PutItemInput{
-		Item:      itemMap,
-		TableName: aws.String(r.dynamodbClient.GetTableName()),
+	// Rooms created before rounds were numbered are on round 1 without one
+	condition := "#data.#round = :prevRound"
+	if round-1 <= 1 {
+		condition = "attribute_exists(PK) AND (attribute_not_exists(#data.#round) OR #data.#round <= :prevRound)"
 	}
 
-	_, err = r.dynamodbClient.PutItem(ctx, input)
-	if err != nil {
-		return fmt.Errorf("failed to put Participant: %v", err)
+	items := []*awsDynamodb.TransactWriteItem{
+		{
+			Update: &awsDynamodb.Update{
+				Key: map[string]*awsDynamodb.AttributeValue{
+					"PK": {
+						S: aws.String(fmt.Sprintf("Room_%s", roomID)),
+					},
+					"SK": {
+						S: aws.String("RoomInfo"),
+					},
+				},
+				ConditionExpression: aws.String(condition),
+				UpdateExpression:    aws.String("SET #data.#roundStartedAt = :roundStartedAt, #data.#round = :round, #data.#parentRound = :parentRound"),
+				ExpressionAttributeNames: map[string]*string{
+					"#data":           aws.String("Data"),
+					"#roundStartedAt": aws.String("round_started_at"),
+					"#round":          aws.String("round"),
+					"#parentRound":    aws.String("parent_round"),
+				},
+				ExpressionAttributeValues: map[string]*awsDynamodb.AttributeValue{
+					":roundStartedAt": roundStartedAt,
+					":round": {
+						N: aws.String(strconv.Itoa(round)),
+					},
+					":parentRound": {
+						N: aws.String(strconv.Itoa(parentRound)),
+					},
+					":prevRound": {
+						N: aws.String(strconv.Itoa(round - 1)),
+					},
+				},
+				TableName: aws.String(r.dynamodbClient.GetTableName()),
+			},
+		},
 	}
 
-	return nil
+	for _, p := range participants {
+		items = append(items, &awsDynamodb.TransactWriteItem{
+			Update: &awsDynamodb.Update{
+				Key:                 participantKey(p.RoomID, p.Name),
+				ConditionExpression: aws.String("attribute_exists(PK)"),
+				UpdateExpression:    aws.String("SET #data.#vote = :empty, #data.#votedAt = :zeroTime REMOVE #data.#comment, #data.#previousVotes"),
+				ExpressionAttributeNames: map[string]*string{
+					"#data":          aws.String("Data"),
+					"#vote":          aws.String("latest_vote"),
+					"#votedAt":       aws.String("voted_at"),
+					"#comment":       aws.String("comment"),
+					"#previousVotes": aws.String("previous_votes"),
+				},
+				ExpressionAttributeValues: map[string]*awsDynamodb.AttributeValue{
+					":empty": {
+						S: aws.String(""),
+					},
+					":zeroTime": zeroTime,
+				},
+				TableName: aws.String(r.dynamodbClient.GetTableName()),
+			},
+		})
+	}
+
+	return items, nil
 }
 
-func (r *Repository) CreateParticipant(ctx context.Context, roomID string, name string, isAdmin bool) (*types.Participant, error) {
+// CreateParticipant stores a new participant and, when event is set, appends it to the room log
+// in the same transaction. userID is the signed in user joining, empty when they're anonymous.
+// Returns ErrAlreadyExists if the name is taken in the room.
+func (r *Repository) CreateParticipant(ctx context.Context, roomID string, name string, isAdmin bool, userID string, event *schema.Event) (*types.Participant, error) {
 	participant := &types.Participant{
 		RoomID:    roomID,
 		Name:      name,
 		IsAdmin:   isAdmin,
 		CreatedAt: time.Now(),
+		UserID:    userID,
+	}
+
+	put, err := r.participantPut(participant, "attribute_not_exists(PK)")
+	if err != nil {
+		return nil, err
 	}
 
+	err = r.transactWithEvent(ctx, event, put)
+	if err != nil {
+		if errors.Is(err, errConditionFailed) {
+			return nil, ErrAlreadyExists
+		}
+		if errors.Is(err, ErrConflict) {
+			return nil, ErrConflict
+		}
+		return nil, fmt.Errorf("failed to put Participant: %v", err)
+	}
+
+	return participant, nil
+}
+
+// DeleteParticipant removes the participant and appends event to the room log in the same transaction
+func (r *Repository) DeleteParticipant(ctx context.Context, roomID, name string, event *schema.Event) error {
+	del := &awsDynamodb.TransactWriteItem{
+		Delete: &awsDynamodb.Delete{
+			Key:                 participantKey(roomID, name),
+			ConditionExpression: aws.String("attribute_exists(PK)"),
+			TableName:           aws.String(r.dynamodbClient.GetTableName()),
+		},
+	}
+
+	err := r.transactWithEvent(ctx, event, del)
+	if err != nil {
+		if errors.Is(err, errConditionFailed) {
+			return ErrNotFound
+		}
+		if errors.Is(err, ErrConflict) {
+			return ErrConflict
+		}
+		return fmt.Errorf("failed to delete Participant: %v", err)
+	}
+
+	return nil
+}
+
+// RenameParticipant moves the participant to newName and appends event to the room log in the
+// same transaction. Returns ErrNotFound if the participant already left or was renamed and
+// ErrAlreadyExists if newName is taken in the room.
+func (r *Repository) RenameParticipant(ctx context.Context, participant *types.Participant, newName string, event *schema.Event) (*types.Participant, error) {
+	renamed := *participant
+	renamed.Name = newName
+
+	del := &awsDynamodb.TransactWriteItem{
+		Delete: &awsDynamodb.Delete{
+			Key:                 participantKey(participant.RoomID, participant.Name),
+			ConditionExpression: aws.String("attribute_exists(PK)"),
+			TableName:           aws.String(r.dynamodbClient.GetTableName()),
+		},
+	}
+
+	put, err := r.participantPut(&renamed, "attribute_not_exists(PK)")
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.transactWithEvent(ctx, event, del, put)
+	if err != nil {
+		if isConditionFailedAt(err, 0) {
+			return nil, ErrNotFound
+		}
+		if errors.Is(err, errConditionFailed) {
+			return nil, ErrAlreadyExists
+		}
+		if errors.Is(err, ErrConflict) {
+			return nil, ErrConflict
+		}
+		return nil, fmt.Errorf("failed to rename Participant: %v", err)
+	}
+
+	return &renamed, nil
+}
+
+// RestoreParticipants replaces the room's participant items with participants, used when
+// rebuilding room state from the event log. Rooms with more items than a transaction holds are
+// restored in several.
+func (r *Repository) RestoreParticipants(ctx context.Context, roomID string, participants []types.Participant) error {
+	existing, err := r.FindParticipants(ctx, roomID)
+	if err != nil {
+		return err
+	}
+
+	keep := map[string]bool{}
+	items := []*awsDynamodb.TransactWriteItem{}
+
+	for i := range participants {
+		keep[participants[i].Name] = true
+
+		put, err := r.participantPut(&participants[i], "")
+		if err != nil {
+			return err
+		}
+		items = append(items, put)
+	}
+
+	for _, p := range *existing {
+		if keep[p.Name] {
+			continue
+		}
+
+		items = append(items, &awsDynamodb.TransactWriteItem{
+			Delete: &awsDynamodb.Delete{
+				Key:       participantKey(roomID, p.Name),
+				TableName: aws.String(r.dynamodbClient.GetTableName()),
+			},
+		})
+	}
+
+	// Restores are repairs that are run again when they fail, a room restored in several
+	// transactions can be left partly restored until then
+	for len(items) > 0 {
+		n := len(items)
+		if n > maxTransactItems {
+			n = maxTransactItems
+		}
+
+		err = r.transactWithEvent(ctx, nil, items[:n]...)
+		if err != nil {
+			return fmt.Errorf("failed to restore participants: %v", err)
+		}
+
+		items = items[n:]
+	}
+
+	return nil
+}
+
+func roomInfoKey(roomID string) map[string]*awsDynamodb.AttributeValue {
+	return map[string]*awsDynamodb.AttributeValue{
+		"PK": {
+			S: aws.String(fmt.Sprintf("Room_%s", roomID)),
+		},
+		"SK": {
+			S: aws.String("RoomInfo"),
+		},
+	}
+}
+
+// isRoomInfo reports whether key is the key of the room's item
+func isRoomInfo(key map[string]*awsDynamodb.AttributeValue, roomID string) bool {
+	pk, sk := key["PK"], key["SK"]
+	return pk != nil && sk != nil && aws.StringValue(pk.S) == fmt.Sprintf("Room_%s", roomID) && aws.StringValue(sk.S) == "RoomInfo"
+}
+
+func participantKey(roomID, name string) map[string]*awsDynamodb.AttributeValue {
+	return map[string]*awsDynamodb.AttributeValue{
+		"PK": {
+			S: aws.String(fmt.Sprintf("Room_%s", roomID)),
+		},
+		"SK": {
+			S: aws.String(fmt.Sprintf("Participant_%s", name)),
+		},
+	}
+}
+
+// participantPut builds the put for the participant item, condition is optional
+func (r *Repository) participantPut(participant *types.Participant, condition string) (*awsDynamodb.TransactWriteItem, error) {
 	item := struct {
 		PK   string
 		SK   string
@@ -132,17 +494,16 @@ func (r *Repository) CreateParticipant(ctx context.Context, roomID string, name
 		return nil, fmt.Errorf("failed to ddb marshal result item record, %v", err)
 	}
 
-	input := &awsDynamodb.PutItemInput{
+	put := &awsDynamodb.Put{
 		Item:      itemMap,
 		TableName: aws.String(r.dynamodbClient.GetTableName()),
 	}
 
-	_, err = r.dynamodbClient.PutItem(ctx, input)
-	if err != nil {
-		return nil, fmt.Errorf("failed to put Participant: %v", err)
+	if condition != "" {
+		put.ConditionExpression = aws.String(condition)
 	}
 
-	return participant, nil
+	return &awsDynamodb.TransactWriteItem{Put: put}, nil
 }
 
 func (r *Repository) FindRoom(ctx context.Context, roomID string) (*types.Room, error) {
@@ -157,7 +518,9 @@ func (r *Repository) FindRoom(ctx context.Context, roomID string) (*types.Room,
 				S: aws.String("RoomInfo"),
 			},
 		},
-		TableName: aws.String(r.dynamodbClient.GetTableName()),
+		// Writes check the sequence read here, a stale one would only make them conflict
+		ConsistentRead: aws.Bool(true),
+		TableName:      aws.String(r.dynamodbClient.GetTableName()),
 	}
 
 	output, err := r.dynamodbClient.GetItem(ctx, input)
@@ -222,7 +585,8 @@ func (r *Repository) FindParticipants(ctx context.Context, roomID string) (*[]ty
 				S: aws.String("Participant_"),
 			},
 		},
-		TableName: aws.String(r.dynamodbClient.GetTableName()),
+		ConsistentRead: aws.Bool(true),
+		TableName:      aws.String(r.dynamodbClient.GetTableName()),
 	}
 
 	output, err := r.dynamodbClient.Query(ctx, input)
@@ -242,27 +606,3 @@ func (r *Repository) FindParticipants(ctx context.Context, roomID string) (*[]ty
 
 	return &participants, nil
 }
-
-func (r *Repository) generateRoomID(ctx context.Context) (string, error) {
-	for {
-		rand.Seed(time.Now().UnixNano())
-
-		numCount := 6
-		letters := []rune("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ1234567890")
-
-		b := make([]rune, numCount)
-		for i := range b {
-			b[i] = letters[rand.Intn(len(letters))]
-		}
-		id := string(b)
-
-		_, err := r.FindRoom(ctx, id)
-		if err != nil {
-			if errors.Is(err, ErrNotFound) {
-				return id, nil
-			} else {
-				return "", err
-			}
-		}
-	}
-}
@@ -0,0 +1,62 @@
+package ddbrepository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/jponc/estimatex-serverless/internal/types"
+	"github.com/jponc/estimatex-serverless/pkg/dynamodb"
+)
+
+func TestResetVotesItems(t *testing.T) {
+	client, err := dynamodb.NewClient("us-east-1", "rooms")
+	if err != nil {
+		t.Fatalf("dynamodb.NewClient: %v", err)
+	}
+	r := &Repository{dynamodbClient: client}
+
+	participants := []types.Participant{
+		{RoomID: "abc", Name: "Ana"},
+		{RoomID: "abc", Name: "Ben"},
+	}
+
+	tests := []struct {
+		name      string
+		round     int
+		condition string
+		prevRound string
+	}{
+		{"second round", 2, "attribute_exists(PK) AND (attribute_not_exists(#data.#round) OR #data.#round <= :prevRound)", "1"},
+		{"later round", 5, "#data.#round = :prevRound", "4"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items, err := r.resetVotesItems("abc", tt.round, 0, participants, time.Now())
+			if err != nil {
+				t.Fatalf("resetVotesItems: %v", err)
+			}
+
+			if len(items) != 3 {
+				t.Fatalf("got %d items, want the room and 2 participants", len(items))
+			}
+
+			// Two resets racing can't both start the round
+			room := items[0].Update
+			if got := aws.StringValue(room.ConditionExpression); got != tt.condition {
+				t.Errorf("room condition = %q, want %q", got, tt.condition)
+			}
+			if got := aws.StringValue(room.ExpressionAttributeValues[":prevRound"].N); got != tt.prevRound {
+				t.Errorf(":prevRound = %s, want %s", got, tt.prevRound)
+			}
+
+			// Participants who left aren't recreated
+			for _, item := range items[1:] {
+				if got := aws.StringValue(item.Update.ConditionExpression); got != "attribute_exists(PK)" {
+					t.Errorf("participant condition = %q, want attribute_exists(PK)", got)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,204 @@
+package ddbrepository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	awsDynamodb "github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/jponc/estimatex-serverless/api/schema"
+	"github.com/jponc/estimatex-serverless/internal/types"
+)
+
+// RoundPrefix prefixes the SK of revealed round items
+const RoundPrefix = "Round_"
+
+type roundItem struct {
+	PK     string      `json:"PK"`
+	SK     string      `json:"SK"`
+	GSI1PK string      `json:"GSI1PK,omitempty"`
+	GSI1SK string      `json:"GSI1SK,omitempty"`
+	Data   types.Round `json:"Data"`
+}
+
+// RevealRound stores the revealed round and appends event to the room log in the same
+// transaction. The round's stats are computed from the room read at sequence readAt, ErrConflict
+// is returned if a vote or anything else was logged since. Revealing a round again replaces it
+// until its final estimate is set, after that ErrAlreadyExists is returned. Rounds of a team's
+// session are indexed in the team's history.
+func (r *Repository) RevealRound(ctx context.Context, round *types.Round, readAt int64, event *schema.Event) error {
+	item := roundItem{
+		PK:   fmt.Sprintf("Room_%s", round.RoomID),
+		SK:   roundSK(round.Number),
+		Data: *round,
+	}
+
+	if round.TeamSlug != "" {
+		item.GSI1PK = teamPK(round.TeamSlug)
+		item.GSI1SK = teamIndexSK(TeamRoundPrefix, round.RevealedAt)
+	}
+
+	itemMap, err := dynamodbattribute.MarshalMap(item)
+	if err != nil {
+		return fmt.Errorf("failed to ddb marshal round item, %v", err)
+	}
+
+	put := &awsDynamodb.TransactWriteItem{
+		Put: &awsDynamodb.Put{
+			Item:                itemMap,
+			ConditionExpression: aws.String("attribute_not_exists(#data.#estimate)"),
+			ExpressionAttributeNames: map[string]*string{
+				"#data":     aws.String("Data"),
+				"#estimate": aws.String("final_estimate"),
+			},
+			TableName: aws.String(r.dynamodbClient.GetTableName()),
+		},
+	}
+
+	err = r.transactAtSequence(ctx, event, readAt, put)
+	if err != nil {
+		if errors.Is(err, errConditionFailed) {
+			return ErrAlreadyExists
+		}
+		if errors.Is(err, ErrConflict) {
+			return ErrConflict
+		}
+		return fmt.Errorf("failed to reveal round: %v", err)
+	}
+
+	return nil
+}
+
+// SetFinalEstimate records the estimate agreed on for a revealed round and appends event to the
+// room log in the same transaction. Returns ErrNotFound if the round hasn't been revealed.
+func (r *Repository) SetFinalEstimate(ctx context.Context, roomID string, round int, estimate string, override bool, event *schema.Event) error {
+	finalizedAt, err := dynamodbattribute.Marshal(event.Timestamp)
+	if err != nil {
+		return fmt.Errorf("failed to ddb marshal finalized at, %v", err)
+	}
+
+	update := &awsDynamodb.TransactWriteItem{
+		Update: &awsDynamodb.Update{
+			Key: map[string]*awsDynamodb.AttributeValue{
+				"PK": {
+					S: aws.String(fmt.Sprintf("Room_%s", roomID)),
+				},
+				"SK": {
+					S: aws.String(roundSK(round)),
+				},
+			},
+			ConditionExpression: aws.String("attribute_exists(PK)"),
+			UpdateExpression:    aws.String("SET #data.#estimate = :estimate, #data.#override = :override, #data.#finalizedAt = :finalizedAt"),
+			ExpressionAttributeNames: map[string]*string{
+				"#data":        aws.String("Data"),
+				"#estimate":    aws.String("final_estimate"),
+				"#override":    aws.String("final_estimate_override"),
+				"#finalizedAt": aws.String("finalized_at"),
+			},
+			ExpressionAttributeValues: map[string]*awsDynamodb.AttributeValue{
+				":estimate": {
+					S: aws.String(estimate),
+				},
+				":override": {
+					BOOL: aws.Bool(override),
+				},
+				":finalizedAt": finalizedAt,
+			},
+			TableName: aws.String(r.dynamodbClient.GetTableName()),
+		},
+	}
+
+	err = r.transactWithEvent(ctx, event, update)
+	if err != nil {
+		if errors.Is(err, errConditionFailed) {
+			return ErrNotFound
+		}
+		if errors.Is(err, ErrConflict) {
+			return ErrConflict
+		}
+		return fmt.Errorf("failed to set final estimate: %v", err)
+	}
+
+	return nil
+}
+
+// FindRound returns the revealed round, ErrNotFound when it hasn't been revealed
+func (r *Repository) FindRound(ctx context.Context, roomID string, number int) (*types.Round, error) {
+	i := roundItem{}
+
+	input := &awsDynamodb.GetItemInput{
+		Key: map[string]*awsDynamodb.AttributeValue{
+			"PK": {
+				S: aws.String(fmt.Sprintf("Room_%s", roomID)),
+			},
+			"SK": {
+				S: aws.String(roundSK(number)),
+			},
+		},
+		TableName: aws.String(r.dynamodbClient.GetTableName()),
+	}
+
+	output, err := r.dynamodbClient.GetItem(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get round: %v", err)
+	}
+
+	if output.Item == nil {
+		return nil, ErrNotFound
+	}
+
+	err = dynamodbattribute.UnmarshalMap(output.Item, &i)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal map: %v", err)
+	}
+
+	return &i.Data, nil
+}
+
+// FindRounds returns the room's revealed rounds, oldest first
+func (r *Repository) FindRounds(ctx context.Context, roomID string) ([]types.Round, error) {
+	input := &awsDynamodb.QueryInput{
+		KeyConditionExpression: aws.String("PK = :PK and begins_with(SK, :SK)"),
+		ExpressionAttributeValues: map[string]*awsDynamodb.AttributeValue{
+			":PK": {
+				S: aws.String(fmt.Sprintf("Room_%s", roomID)),
+			},
+			":SK": {
+				S: aws.String(RoundPrefix),
+			},
+		},
+		TableName: aws.String(r.dynamodbClient.GetTableName()),
+	}
+
+	rounds := []types.Round{}
+
+	for {
+		output, err := r.dynamodbClient.Query(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query rounds: %v", err)
+		}
+
+		for _, item := range output.Items {
+			i := roundItem{}
+			err = dynamodbattribute.UnmarshalMap(item, &i)
+			if err != nil {
+				return nil, fmt.Errorf("failed to unmarshal map: %v", err)
+			}
+			rounds = append(rounds, i.Data)
+		}
+
+		if output.LastEvaluatedKey == nil {
+			break
+		}
+		input.ExclusiveStartKey = output.LastEvaluatedKey
+	}
+
+	return rounds, nil
+}
+
+// roundSK zero pads the round number so rounds sort in order
+func roundSK(number int) string {
+	return fmt.Sprintf("%s%010d", RoundPrefix, number)
+}
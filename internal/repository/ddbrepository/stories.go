@@ -0,0 +1,321 @@
+package ddbrepository
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	awsDynamodb "github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/jponc/estimatex-serverless/api/schema"
+	"github.com/jponc/estimatex-serverless/internal/types"
+)
+
+// StoryPrefix prefixes the SK of story items
+const StoryPrefix = "Story_"
+
+type storyItem struct {
+	PK   string      `json:"PK"`
+	SK   string      `json:"SK"`
+	Data types.Story `json:"Data"`
+}
+
+// NewStory instantiates a story with a new ID, it isn't stored until it's added to a room
+func NewStory(roomID, title, description, externalKey, url string) (*types.Story, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return nil, fmt.Errorf("failed to generate story ID: %v", err)
+	}
+
+	story := &types.Story{
+		ID:          hex.EncodeToString(b),
+		RoomID:      roomID,
+		Title:       title,
+		Description: description,
+		ExternalKey: externalKey,
+		URL:         url,
+		CreatedAt:   time.Now(),
+	}
+
+	return story, nil
+}
+
+// AddStories stores stories and sets the room's queue, which the caller has appended them to,
+// and appends event to the room log in the same transaction. The queue is only set if the room
+// hasn't changed since it was read at sequence readAt, ErrConflict is returned otherwise.
+func (r *Repository) AddStories(ctx context.Context, roomID string, stories []types.Story, queue []string, readAt int64, event *schema.Event) error {
+	items := []*awsDynamodb.TransactWriteItem{}
+
+	for _, story := range stories {
+		item := storyItem{
+			PK:   fmt.Sprintf("Room_%s", roomID),
+			SK:   storySK(story.ID),
+			Data: story,
+		}
+
+		itemMap, err := dynamodbattribute.MarshalMap(item)
+		if err != nil {
+			return fmt.Errorf("failed to ddb marshal story item, %v", err)
+		}
+
+		items = append(items, &awsDynamodb.TransactWriteItem{
+			Put: &awsDynamodb.Put{
+				Item:                itemMap,
+				ConditionExpression: aws.String("attribute_not_exists(PK)"),
+				TableName:           aws.String(r.dynamodbClient.GetTableName()),
+			},
+		})
+	}
+
+	queueUpdate, err := r.storyQueueUpdate(roomID, queue)
+	if err != nil {
+		return err
+	}
+	items = append(items, queueUpdate)
+
+	err = r.transactAtSequence(ctx, event, readAt, items...)
+	if err != nil {
+		if errors.Is(err, ErrConflict) {
+			return ErrConflict
+		}
+		return fmt.Errorf("failed to add stories: %v", err)
+	}
+
+	return nil
+}
+
+// PutStories stores stories in batches without adding them to the room's queue, the caller queues
+// the ones written with UpdateStoryQueue. Returns the IDs of the stories that couldn't be written.
+func (r *Repository) PutStories(ctx context.Context, roomID string, stories []types.Story) ([]string, error) {
+	items := []map[string]*awsDynamodb.AttributeValue{}
+
+	for _, story := range stories {
+		item := storyItem{
+			PK:   fmt.Sprintf("Room_%s", roomID),
+			SK:   storySK(story.ID),
+			Data: story,
+		}
+
+		itemMap, err := dynamodbattribute.MarshalMap(item)
+		if err != nil {
+			return nil, fmt.Errorf("failed to ddb marshal story item, %v", err)
+		}
+
+		items = append(items, itemMap)
+	}
+
+	unprocessed, err := r.batchPut(ctx, items)
+	if err != nil {
+		return nil, fmt.Errorf("failed to put stories: %v", err)
+	}
+
+	failedIDs := []string{}
+	for _, item := range unprocessed {
+		failedIDs = append(failedIDs, strings.TrimPrefix(itemSK(item), StoryPrefix))
+	}
+
+	return failedIDs, nil
+}
+
+// UpdateStoryQueue sets the room's queue and appends event to the room log in the same
+// transaction. Returns ErrConflict if the room changed since it was read at sequence readAt.
+func (r *Repository) UpdateStoryQueue(ctx context.Context, roomID string, queue []string, readAt int64, event *schema.Event) error {
+	queueUpdate, err := r.storyQueueUpdate(roomID, queue)
+	if err != nil {
+		return err
+	}
+
+	err = r.transactAtSequence(ctx, event, readAt, queueUpdate)
+	if err != nil {
+		if errors.Is(err, ErrConflict) {
+			return ErrConflict
+		}
+		return fmt.Errorf("failed to update story queue: %v", err)
+	}
+
+	return nil
+}
+
+// RemoveStory deletes the story and sets the room's queue, which the caller has removed it from,
+// and appends event to the room log in the same transaction. Returns ErrNotFound if there's no
+// such story and ErrConflict if the room changed since it was read at sequence readAt.
+func (r *Repository) RemoveStory(ctx context.Context, roomID, storyID string, queue []string, readAt int64, event *schema.Event) error {
+	del := &awsDynamodb.TransactWriteItem{
+		Delete: &awsDynamodb.Delete{
+			Key:                 storyKey(roomID, storyID),
+			ConditionExpression: aws.String("attribute_exists(PK)"),
+			TableName:           aws.String(r.dynamodbClient.GetTableName()),
+		},
+	}
+
+	queueUpdate, err := r.storyQueueUpdate(roomID, queue)
+	if err != nil {
+		return err
+	}
+
+	err = r.transactAtSequence(ctx, event, readAt, del, queueUpdate)
+	if err != nil {
+		if errors.Is(err, errConditionFailed) {
+			return ErrNotFound
+		}
+		if errors.Is(err, ErrConflict) {
+			return ErrConflict
+		}
+		return fmt.Errorf("failed to remove story: %v", err)
+	}
+
+	return nil
+}
+
+// StartStory makes storyID the room's current story, sets the queue it was taken from and starts
+// round with every vote cleared, appending event to the room log in the same transaction. An
+// empty storyID leaves the room without a current story. The queue, round and participants are
+// read from the room at sequence readAt, ErrConflict is returned if it changed since.
+func (r *Repository) StartStory(ctx context.Context, roomID, storyID string, queue []string, round int, participants []types.Participant, readAt int64, event *schema.Event) error {
+	items, err := r.resetVotesItems(roomID, round, 0, participants, event.Timestamp)
+	if err != nil {
+		return err
+	}
+
+	q, err := dynamodbattribute.Marshal(queue)
+	if err != nil {
+		return fmt.Errorf("failed to ddb marshal story queue, %v", err)
+	}
+
+	// Extend the room update with the story change
+	roomUpdate := items[0].Update
+	roomUpdate.UpdateExpression = aws.String(*roomUpdate.UpdateExpression + ", #data.#currentStory = :currentStory, #data.#queue = :queue")
+	roomUpdate.ExpressionAttributeNames["#currentStory"] = aws.String("current_story_id")
+	roomUpdate.ExpressionAttributeNames["#queue"] = aws.String("story_queue")
+	roomUpdate.ExpressionAttributeValues[":currentStory"] = &awsDynamodb.AttributeValue{S: aws.String(storyID)}
+	roomUpdate.ExpressionAttributeValues[":queue"] = q
+
+	err = r.transactAtSequence(ctx, event, readAt, items...)
+	if err != nil {
+		if errors.Is(err, ErrConflict) || errors.Is(err, errConditionFailed) {
+			return ErrConflict
+		}
+		return fmt.Errorf("failed to start story: %v", err)
+	}
+
+	return nil
+}
+
+// FindStory returns the room's story, ErrNotFound when there's none with storyID
+func (r *Repository) FindStory(ctx context.Context, roomID, storyID string) (*types.Story, error) {
+	i := storyItem{}
+
+	input := &awsDynamodb.GetItemInput{
+		Key:       storyKey(roomID, storyID),
+		TableName: aws.String(r.dynamodbClient.GetTableName()),
+	}
+
+	output, err := r.dynamodbClient.GetItem(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get story: %v", err)
+	}
+
+	if output.Item == nil {
+		return nil, ErrNotFound
+	}
+
+	err = dynamodbattribute.UnmarshalMap(output.Item, &i)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal map: %v", err)
+	}
+
+	return &i.Data, nil
+}
+
+// FindStories returns every story of the room keyed by ID, including ones already estimated
+func (r *Repository) FindStories(ctx context.Context, roomID string) (map[string]types.Story, error) {
+	input := &awsDynamodb.QueryInput{
+		KeyConditionExpression: aws.String("PK = :PK and begins_with(SK, :SK)"),
+		ExpressionAttributeValues: map[string]*awsDynamodb.AttributeValue{
+			":PK": {
+				S: aws.String(fmt.Sprintf("Room_%s", roomID)),
+			},
+			":SK": {
+				S: aws.String(StoryPrefix),
+			},
+		},
+		TableName: aws.String(r.dynamodbClient.GetTableName()),
+	}
+
+	stories := map[string]types.Story{}
+
+	for {
+		output, err := r.dynamodbClient.Query(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query stories: %v", err)
+		}
+
+		for _, item := range output.Items {
+			i := storyItem{}
+			err = dynamodbattribute.UnmarshalMap(item, &i)
+			if err != nil {
+				return nil, fmt.Errorf("failed to unmarshal map: %v", err)
+			}
+			stories[i.Data.ID] = i.Data
+		}
+
+		if output.LastEvaluatedKey == nil {
+			break
+		}
+		input.ExclusiveStartKey = output.LastEvaluatedKey
+	}
+
+	return stories, nil
+}
+
+// storyQueueUpdate builds the update replacing the room's queue, it's written with
+// transactAtSequence so a queue changed since it was read isn't overwritten
+func (r *Repository) storyQueueUpdate(roomID string, queue []string) (*awsDynamodb.TransactWriteItem, error) {
+	q, err := dynamodbattribute.Marshal(queue)
+	if err != nil {
+		return nil, fmt.Errorf("failed to ddb marshal story queue, %v", err)
+	}
+
+	return &awsDynamodb.TransactWriteItem{
+		Update: &awsDynamodb.Update{
+			Key: map[string]*awsDynamodb.AttributeValue{
+				"PK": {
+					S: aws.String(fmt.Sprintf("Room_%s", roomID)),
+				},
+				"SK": {
+					S: aws.String("RoomInfo"),
+				},
+			},
+			ConditionExpression: aws.String("attribute_exists(PK)"),
+			UpdateExpression:    aws.String("SET #data.#queue = :queue"),
+			ExpressionAttributeNames: map[string]*string{
+				"#data":  aws.String("Data"),
+				"#queue": aws.String("story_queue"),
+			},
+			ExpressionAttributeValues: map[string]*awsDynamodb.AttributeValue{
+				":queue": q,
+			},
+			TableName: aws.String(r.dynamodbClient.GetTableName()),
+		},
+	}, nil
+}
+
+func storyKey(roomID, storyID string) map[string]*awsDynamodb.AttributeValue {
+	return map[string]*awsDynamodb.AttributeValue{
+		"PK": {
+			S: aws.String(fmt.Sprintf("Room_%s", roomID)),
+		},
+		"SK": {
+			S: aws.String(storySK(storyID)),
+		},
+	}
+}
+
+func storySK(storyID string) string {
+	return StoryPrefix + storyID
+}
@@ -0,0 +1,322 @@
+package ddbrepository
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	awsDynamodb "github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/jponc/estimatex-serverless/internal/types"
+)
+
+// teamInfoSK is the SK of the team item, under PK Team_<slug>
+const teamInfoSK = "TeamInfo"
+
+// teamIndex is the GSI keyed on GSI1PK Team_<slug>, it lists the team's sessions and rounds
+const teamIndex = "GSI1"
+
+const (
+	// TeamSessionPrefix prefixes the GSI1SK of the RoomInfo items of a team's sessions
+	TeamSessionPrefix = "Session_"
+	// TeamRoundPrefix prefixes the GSI1SK of the revealed rounds of a team's sessions
+	TeamRoundPrefix = "Round_"
+)
+
+// StoredTeam is a team with the hash of the key its admins manage it with
+type StoredTeam struct {
+	types.Team
+	KeyHash string
+}
+
+// KeyMatches reports whether key is the team's key
+func (t *StoredTeam) KeyMatches(key string) bool {
+	return subtle.ConstantTimeCompare([]byte(hashTeamKey(key)), []byte(t.KeyHash)) == 1
+}
+
+// TeamHistory is the most recent sessions and revealed rounds across a team's sessions
+type TeamHistory struct {
+	Sessions []types.Room
+	Rounds   []types.Round
+}
+
+type teamItem struct {
+	PK      string     `json:"PK"`
+	SK      string     `json:"SK"`
+	Data    types.Team `json:"Data"`
+	KeyHash string     `json:"KeyHash"`
+}
+
+// NewTeamKey returns a new team key and the hash it's stored as, the key itself is only shown once
+func NewTeamKey() (string, string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", fmt.Errorf("failed to generate team key: %v", err)
+	}
+
+	key := "team_" + hex.EncodeToString(b)
+
+	return key, hashTeamKey(key), nil
+}
+
+// CreateTeam stores team, ErrAlreadyExists when its slug is taken
+func (r *Repository) CreateTeam(ctx context.Context, team StoredTeam) error {
+	item := teamItem{
+		PK:      teamPK(team.Slug),
+		SK:      teamInfoSK,
+		Data:    team.Team,
+		KeyHash: team.KeyHash,
+	}
+
+	itemMap, err := dynamodbattribute.MarshalMap(item)
+	if err != nil {
+		return fmt.Errorf("failed to ddb marshal team item, %v", err)
+	}
+
+	input := &awsDynamodb.PutItemInput{
+		Item:                itemMap,
+		ConditionExpression: aws.String("attribute_not_exists(PK)"),
+		TableName:           aws.String(r.dynamodbClient.GetTableName()),
+	}
+
+	_, err = r.dynamodbClient.PutItem(ctx, input)
+	if err != nil {
+		if isConditionalCheckFailed(err) {
+			return ErrAlreadyExists
+		}
+		return fmt.Errorf("failed to put team: %v", err)
+	}
+
+	return nil
+}
+
+// FindTeam returns the team with slug, ErrNotFound when there's none
+func (r *Repository) FindTeam(ctx context.Context, slug string) (*StoredTeam, error) {
+	i := teamItem{}
+
+	input := &awsDynamodb.GetItemInput{
+		Key:       teamKey(slug),
+		TableName: aws.String(r.dynamodbClient.GetTableName()),
+	}
+
+	output, err := r.dynamodbClient.GetItem(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get team: %v", err)
+	}
+
+	if output.Item == nil {
+		return nil, ErrNotFound
+	}
+
+	err = dynamodbattribute.UnmarshalMap(output.Item, &i)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal map: %v", err)
+	}
+
+	return &StoredTeam{Team: i.Data, KeyHash: i.KeyHash}, nil
+}
+
+// UpdateTeam replaces the team's name and session defaults, leaving its current session alone.
+// Returns ErrNotFound if the team doesn't exist.
+func (r *Repository) UpdateTeam(ctx context.Context, team types.Team) error {
+	values := map[string]interface{}{
+		":name":        team.Name,
+		":deck":        team.Deck,
+		":defaultRole": team.DefaultRole,
+		":members":     team.Members,
+		":updatedAt":   team.UpdatedAt,
+	}
+
+	attributeValues := map[string]*awsDynamodb.AttributeValue{}
+	for k, v := range values {
+		av, err := dynamodbattribute.Marshal(v)
+		if err != nil {
+			return fmt.Errorf("failed to ddb marshal %s, %v", k, err)
+		}
+		attributeValues[k] = av
+	}
+
+	input := &awsDynamodb.UpdateItemInput{
+		Key:                 teamKey(team.Slug),
+		ConditionExpression: aws.String("attribute_exists(PK)"),
+		UpdateExpression:    aws.String("SET #data.#name = :name, #data.#deck = :deck, #data.#defaultRole = :defaultRole, #data.#members = :members, #data.#updatedAt = :updatedAt"),
+		ExpressionAttributeNames: map[string]*string{
+			"#data":        aws.String("Data"),
+			"#name":        aws.String("name"),
+			"#deck":        aws.String("deck"),
+			"#defaultRole": aws.String("default_role"),
+			"#members":     aws.String("members"),
+			"#updatedAt":   aws.String("updated_at"),
+		},
+		ExpressionAttributeValues: attributeValues,
+		TableName:                 aws.String(r.dynamodbClient.GetTableName()),
+	}
+
+	_, err := r.dynamodbClient.UpdateItem(ctx, input)
+	if err != nil {
+		if isConditionalCheckFailed(err) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("failed to update team: %v", err)
+	}
+
+	return nil
+}
+
+// teamCurrentRoomUpdate points the team's slug at roomID, the session it opened last
+func (r *Repository) teamCurrentRoomUpdate(slug, roomID string) *awsDynamodb.Update {
+	return &awsDynamodb.Update{
+		Key:                 teamKey(slug),
+		ConditionExpression: aws.String("attribute_exists(PK)"),
+		UpdateExpression:    aws.String("SET #data.#currentRoomID = :roomID"),
+		ExpressionAttributeNames: map[string]*string{
+			"#data":          aws.String("Data"),
+			"#currentRoomID": aws.String("current_room_id"),
+		},
+		ExpressionAttributeValues: map[string]*awsDynamodb.AttributeValue{
+			":roomID": {
+				S: aws.String(roomID),
+			},
+		},
+		TableName: aws.String(r.dynamodbClient.GetTableName()),
+	}
+}
+
+// FindTeamHistory returns the team's latest sessions and latest revealed rounds across all of
+// them, up to limit of each, newest first
+func (r *Repository) FindTeamHistory(ctx context.Context, slug string, limit int) (*TeamHistory, error) {
+	sessions, err := r.FindTeamSessions(ctx, slug, time.Time{}, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	history := &TeamHistory{
+		Sessions: sessions,
+		Rounds:   []types.Round{},
+	}
+
+	err = r.queryTeamIndex(ctx, slug, TeamRoundPrefix, time.Time{}, limit, func(item map[string]*awsDynamodb.AttributeValue) error {
+		i := roundItem{}
+		err := dynamodbattribute.UnmarshalMap(item, &i)
+		if err != nil {
+			return err
+		}
+
+		history.Rounds = append(history.Rounds, i.Data)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query team rounds: %v", err)
+	}
+
+	return history, nil
+}
+
+// FindTeamSessions returns up to limit of the team's sessions created before before, newest
+// first. A zero before starts from the latest session.
+func (r *Repository) FindTeamSessions(ctx context.Context, slug string, before time.Time, limit int) ([]types.Room, error) {
+	sessions := []types.Room{}
+
+	err := r.queryTeamIndex(ctx, slug, TeamSessionPrefix, before, limit, func(item map[string]*awsDynamodb.AttributeValue) error {
+		i := roomItem{}
+		err := dynamodbattribute.UnmarshalMap(item, &i)
+		if err != nil {
+			return err
+		}
+
+		sessions = append(sessions, i.Data)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query team sessions: %v", err)
+	}
+
+	return sessions, nil
+}
+
+// queryTeamIndex calls fn with the team's indexed items whose GSI1SK starts with prefix, newest
+// first, stopping after limit items when limit is positive. A non zero before skips the items
+// indexed at or after it.
+func (r *Repository) queryTeamIndex(ctx context.Context, slug, prefix string, before time.Time, limit int, fn func(item map[string]*awsDynamodb.AttributeValue) error) error {
+	input := &awsDynamodb.QueryInput{
+		IndexName:              aws.String(teamIndex),
+		KeyConditionExpression: aws.String("GSI1PK = :PK and begins_with(GSI1SK, :SK)"),
+		ExpressionAttributeValues: map[string]*awsDynamodb.AttributeValue{
+			":PK": {
+				S: aws.String(teamPK(slug)),
+			},
+			":SK": {
+				S: aws.String(prefix),
+			},
+		},
+		ScanIndexForward: aws.Bool(false),
+		TableName:        aws.String(r.dynamodbClient.GetTableName()),
+	}
+
+	if !before.IsZero() {
+		input.KeyConditionExpression = aws.String("GSI1PK = :PK and GSI1SK BETWEEN :SK and :before")
+		input.ExpressionAttributeValues[":before"] = &awsDynamodb.AttributeValue{
+			S: aws.String(teamIndexSK(prefix, before.Add(-time.Nanosecond))),
+		}
+	}
+
+	if limit > 0 {
+		input.Limit = aws.Int64(int64(limit))
+	}
+
+	count := 0
+
+	for {
+		output, err := r.dynamodbClient.Query(ctx, input)
+		if err != nil {
+			return err
+		}
+
+		for _, item := range output.Items {
+			err = fn(item)
+			if err != nil {
+				return fmt.Errorf("failed to unmarshal map: %v", err)
+			}
+
+			count++
+			if limit > 0 && count == limit {
+				return nil
+			}
+		}
+
+		if output.LastEvaluatedKey == nil {
+			return nil
+		}
+		input.ExclusiveStartKey = output.LastEvaluatedKey
+	}
+}
+
+func teamPK(slug string) string {
+	return fmt.Sprintf("Team_%s", slug)
+}
+
+// teamIndexSK zero pads the timestamp so the team's index sorts chronologically
+func teamIndexSK(prefix string, at time.Time) string {
+	return fmt.Sprintf("%s%020d", prefix, at.UnixNano())
+}
+
+func teamKey(slug string) map[string]*awsDynamodb.AttributeValue {
+	return map[string]*awsDynamodb.AttributeValue{
+		"PK": {
+			S: aws.String(teamPK(slug)),
+		},
+		"SK": {
+			S: aws.String(teamInfoSK),
+		},
+	}
+}
+
+func hashTeamKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,93 @@
+package ddbrepository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	awsDynamodb "github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/jponc/estimatex-serverless/internal/types"
+)
+
+// userInfoSK is the SK of the user item, under PK User_<id>
+const userInfoSK = "UserInfo"
+
+type userItem struct {
+	PK   string     `json:"PK"`
+	SK   string     `json:"SK"`
+	Data types.User `json:"Data"`
+}
+
+// SaveUser stores user, replacing the profile of a returning user but keeping when they were
+// first seen
+func (r *Repository) SaveUser(ctx context.Context, user types.User) (*types.User, error) {
+	existing, err := r.FindUser(ctx, user.ID)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+	if existing != nil {
+		user.CreatedAt = existing.CreatedAt
+	}
+
+	item := userItem{
+		PK:   userPK(user.ID),
+		SK:   userInfoSK,
+		Data: user,
+	}
+
+	itemMap, err := dynamodbattribute.MarshalMap(item)
+	if err != nil {
+		return nil, fmt.Errorf("failed to ddb marshal user item, %v", err)
+	}
+
+	input := &awsDynamodb.PutItemInput{
+		Item:      itemMap,
+		TableName: aws.String(r.dynamodbClient.GetTableName()),
+	}
+
+	_, err = r.dynamodbClient.PutItem(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to put user: %v", err)
+	}
+
+	return &user, nil
+}
+
+// FindUser returns the user with userID, ErrNotFound when there's none
+func (r *Repository) FindUser(ctx context.Context, userID string) (*types.User, error) {
+	i := userItem{}
+
+	input := &awsDynamodb.GetItemInput{
+		Key: map[string]*awsDynamodb.AttributeValue{
+			"PK": {
+				S: aws.String(userPK(userID)),
+			},
+			"SK": {
+				S: aws.String(userInfoSK),
+			},
+		},
+		TableName: aws.String(r.dynamodbClient.GetTableName()),
+	}
+
+	output, err := r.dynamodbClient.GetItem(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %v", err)
+	}
+
+	if output.Item == nil {
+		return nil, ErrNotFound
+	}
+
+	err = dynamodbattribute.UnmarshalMap(output.Item, &i)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal map: %v", err)
+	}
+
+	return &i.Data, nil
+}
+
+func userPK(userID string) string {
+	return fmt.Sprintf("User_%s", userID)
+}
@@ -0,0 +1,248 @@
+package ddbrepository
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	awsDynamodb "github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/jponc/estimatex-serverless/internal/types"
+)
+
+const (
+	// WebhookPrefix prefixes the SK of webhook items
+	WebhookPrefix = "Webhook_"
+	// WebhookDeliveryPrefix prefixes the SK of delivery log items, which sort oldest first
+	WebhookDeliveryPrefix = "WebhookDelivery_"
+)
+
+// webhookDeliveryTTL is how long the delivery log is kept
+const webhookDeliveryTTL = 7 * 24 * time.Hour
+
+// StoredWebhook is a webhook with the secret its deliveries are signed with
+type StoredWebhook struct {
+	types.Webhook
+	Secret string
+}
+
+type webhookItem struct {
+	PK     string        `json:"PK"`
+	SK     string        `json:"SK"`
+	Data   types.Webhook `json:"Data"`
+	Secret string        `json:"Secret"`
+}
+
+type webhookDeliveryItem struct {
+	PK   string                `json:"PK"`
+	SK   string                `json:"SK"`
+	Data types.WebhookDelivery `json:"Data"`
+	TTL  int64                 `json:"TTL"`
+}
+
+// NewWebhook instantiates a webhook with a new ID and signing secret, it isn't stored until it's saved
+func NewWebhook(url string, eventTypes []string) (*StoredWebhook, error) {
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return nil, fmt.Errorf("failed to generate webhook ID: %v", err)
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("failed to generate webhook secret: %v", err)
+	}
+
+	if eventTypes == nil {
+		eventTypes = []string{}
+	}
+
+	webhook := &StoredWebhook{
+		Webhook: types.Webhook{
+			ID:         hex.EncodeToString(id),
+			URL:        url,
+			EventTypes: eventTypes,
+			CreatedAt:  time.Now(),
+		},
+		Secret: "whsec_" + hex.EncodeToString(secret),
+	}
+
+	return webhook, nil
+}
+
+// SaveWebhook stores the room's webhook, ErrAlreadyExists when its ID is taken
+func (r *Repository) SaveWebhook(ctx context.Context, roomID string, webhook StoredWebhook) error {
+	item := webhookItem{
+		PK:     fmt.Sprintf("Room_%s", roomID),
+		SK:     WebhookPrefix + webhook.ID,
+		Data:   webhook.Webhook,
+		Secret: webhook.Secret,
+	}
+
+	itemMap, err := dynamodbattribute.MarshalMap(item)
+	if err != nil {
+		return fmt.Errorf("failed to ddb marshal webhook item, %v", err)
+	}
+
+	input := &awsDynamodb.PutItemInput{
+		Item:                itemMap,
+		ConditionExpression: aws.String("attribute_not_exists(PK)"),
+		TableName:           aws.String(r.dynamodbClient.GetTableName()),
+	}
+
+	_, err = r.dynamodbClient.PutItem(ctx, input)
+	if err != nil {
+		if isConditionalCheckFailed(err) {
+			return ErrAlreadyExists
+		}
+		return fmt.Errorf("failed to put webhook: %v", err)
+	}
+
+	return nil
+}
+
+// RemoveWebhook deletes the room's webhook, ErrNotFound when there's none with webhookID
+func (r *Repository) RemoveWebhook(ctx context.Context, roomID, webhookID string) error {
+	input := &awsDynamodb.DeleteItemInput{
+		Key: map[string]*awsDynamodb.AttributeValue{
+			"PK": {
+				S: aws.String(fmt.Sprintf("Room_%s", roomID)),
+			},
+			"SK": {
+				S: aws.String(WebhookPrefix + webhookID),
+			},
+		},
+		ConditionExpression: aws.String("attribute_exists(PK)"),
+		TableName:           aws.String(r.dynamodbClient.GetTableName()),
+	}
+
+	_, err := r.dynamodbClient.DeleteItem(ctx, input)
+	if err != nil {
+		if isConditionalCheckFailed(err) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("failed to delete webhook: %v", err)
+	}
+
+	return nil
+}
+
+// FindWebhooks returns the room's webhooks with their secrets
+func (r *Repository) FindWebhooks(ctx context.Context, roomID string) ([]StoredWebhook, error) {
+	webhooks := []StoredWebhook{}
+
+	err := r.queryPrefix(ctx, roomID, WebhookPrefix, false, 0, func(item map[string]*awsDynamodb.AttributeValue) error {
+		i := webhookItem{}
+		err := dynamodbattribute.UnmarshalMap(item, &i)
+		if err != nil {
+			return err
+		}
+
+		webhooks = append(webhooks, StoredWebhook{Webhook: i.Data, Secret: i.Secret})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query webhooks: %v", err)
+	}
+
+	return webhooks, nil
+}
+
+// SaveWebhookDelivery appends delivery to the room's delivery log, it expires after a week
+func (r *Repository) SaveWebhookDelivery(ctx context.Context, roomID string, delivery types.WebhookDelivery) error {
+	item := webhookDeliveryItem{
+		PK: fmt.Sprintf("Room_%s", roomID),
+		// The zero padded timestamp keeps the log in delivery order
+		SK:   fmt.Sprintf("%s%020d_%s", WebhookDeliveryPrefix, delivery.DeliveredAt.UnixNano(), delivery.ID),
+		Data: delivery,
+		TTL:  delivery.DeliveredAt.Add(webhookDeliveryTTL).Unix(),
+	}
+
+	itemMap, err := dynamodbattribute.MarshalMap(item)
+	if err != nil {
+		return fmt.Errorf("failed to ddb marshal webhook delivery item, %v", err)
+	}
+
+	input := &awsDynamodb.PutItemInput{
+		Item:      itemMap,
+		TableName: aws.String(r.dynamodbClient.GetTableName()),
+	}
+
+	_, err = r.dynamodbClient.PutItem(ctx, input)
+	if err != nil {
+		return fmt.Errorf("failed to put webhook delivery: %v", err)
+	}
+
+	return nil
+}
+
+// FindWebhookDeliveries returns the room's most recent deliveries, newest first
+func (r *Repository) FindWebhookDeliveries(ctx context.Context, roomID string, limit int) ([]types.WebhookDelivery, error) {
+	deliveries := []types.WebhookDelivery{}
+
+	err := r.queryPrefix(ctx, roomID, WebhookDeliveryPrefix, true, limit, func(item map[string]*awsDynamodb.AttributeValue) error {
+		i := webhookDeliveryItem{}
+		err := dynamodbattribute.UnmarshalMap(item, &i)
+		if err != nil {
+			return err
+		}
+
+		deliveries = append(deliveries, i.Data)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query webhook deliveries: %v", err)
+	}
+
+	return deliveries, nil
+}
+
+// queryPrefix calls fn with every item of the room whose SK starts with prefix, stopping after
+// limit items when limit is positive
+func (r *Repository) queryPrefix(ctx context.Context, roomID, prefix string, newestFirst bool, limit int, fn func(item map[string]*awsDynamodb.AttributeValue) error) error {
+	input := &awsDynamodb.QueryInput{
+		KeyConditionExpression: aws.String("PK = :PK and begins_with(SK, :SK)"),
+		ExpressionAttributeValues: map[string]*awsDynamodb.AttributeValue{
+			":PK": {
+				S: aws.String(fmt.Sprintf("Room_%s", roomID)),
+			},
+			":SK": {
+				S: aws.String(prefix),
+			},
+		},
+		ScanIndexForward: aws.Bool(!newestFirst),
+		TableName:        aws.String(r.dynamodbClient.GetTableName()),
+	}
+
+	if limit > 0 {
+		input.Limit = aws.Int64(int64(limit))
+	}
+
+	count := 0
+
+	for {
+		output, err := r.dynamodbClient.Query(ctx, input)
+		if err != nil {
+			return err
+		}
+
+		for _, item := range output.Items {
+			err = fn(item)
+			if err != nil {
+				return fmt.Errorf("failed to unmarshal map: %v", err)
+			}
+
+			count++
+			if limit > 0 && count == limit {
+				return nil
+			}
+		}
+
+		if output.LastEvaluatedKey == nil {
+			return nil
+		}
+		input.ExclusiveStartKey = output.LastEvaluatedKey
+	}
+}
@@ -0,0 +1,125 @@
+package roomid
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// DefaultAlphabet leaves out characters that are easily confused when read aloud or handwritten:
+// 0/O, 1/I/L and lowercase letters
+const DefaultAlphabet = "23456789ABCDEFGHJKMNPQRSTUVWXYZ"
+
+// DefaultLength gives 31^6, about 887 million, codes with DefaultAlphabet
+const DefaultLength = 6
+
+// wordSeparator joins the words of a word based code
+const wordSeparator = "-"
+
+// Generator creates random room IDs from crypto/rand, either as characters from an alphabet or as
+// words from a word list
+type Generator struct {
+	alphabet []rune
+	words    []string
+	length   int
+}
+
+// NewGenerator instantiates a generator of length characters from alphabet, which may only hold
+// ASCII letters and digits so IDs are safe in keys, URLs and Pusher channel names
+func NewGenerator(alphabet string, length int) (*Generator, error) {
+	runes := []rune(alphabet)
+	seen := map[rune]bool{}
+	for _, r := range runes {
+		if !isAlphanumeric(r) {
+			return nil, fmt.Errorf("alphabet has invalid character %q", r)
+		}
+		if seen[r] {
+			return nil, fmt.Errorf("alphabet has duplicate character %q", r)
+		}
+		seen[r] = true
+	}
+
+	if len(runes) < 2 {
+		return nil, fmt.Errorf("alphabet needs at least 2 characters")
+	}
+
+	if length < 4 || length > 32 {
+		return nil, fmt.Errorf("length must be between 4 and 32, got %d", length)
+	}
+
+	g := &Generator{
+		alphabet: runes,
+		length:   length,
+	}
+
+	return g, nil
+}
+
+// NewWordGenerator instantiates a generator of count words from words joined by dashes, e.g.
+// "maple-otter-comet"
+func NewWordGenerator(words []string, count int) (*Generator, error) {
+	if len(words) < 2 {
+		return nil, fmt.Errorf("word list needs at least 2 words")
+	}
+
+	for _, w := range words {
+		for _, r := range w {
+			if r < 'a' || r > 'z' {
+				return nil, fmt.Errorf("word %q must only hold lowercase letters", w)
+			}
+		}
+	}
+
+	if count < 2 || count > 8 {
+		return nil, fmt.Errorf("word count must be between 2 and 8, got %d", count)
+	}
+
+	g := &Generator{
+		words:  words,
+		length: count,
+	}
+
+	return g, nil
+}
+
+// Generate returns a new random room ID, callers must still handle it already being taken
+func (g *Generator) Generate() (string, error) {
+	if g.words != nil {
+		parts := make([]string, g.length)
+		for i := range parts {
+			n, err := randomIndex(len(g.words))
+			if err != nil {
+				return "", err
+			}
+			parts[i] = g.words[n]
+		}
+
+		return strings.Join(parts, wordSeparator), nil
+	}
+
+	b := make([]rune, g.length)
+	for i := range b {
+		n, err := randomIndex(len(g.alphabet))
+		if err != nil {
+			return "", err
+		}
+		b[i] = g.alphabet[n]
+	}
+
+	return string(b), nil
+}
+
+// randomIndex returns a uniformly random index below n
+func randomIndex(n int) (int, error) {
+	i, err := rand.Int(rand.Reader, big.NewInt(int64(n)))
+	if err != nil {
+		return 0, fmt.Errorf("failed to read random number: %v", err)
+	}
+
+	return int(i.Int64()), nil
+}
+
+func isAlphanumeric(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')
+}
@@ -0,0 +1,26 @@
+package roomid
+
+// Words are short, distinct words that are easy to say and spell, used for word based room codes
+var Words = []string{
+	"acorn", "amber", "anchor", "apple", "arrow", "aspen", "atlas", "autumn", "badge", "bamboo",
+	"banjo", "basil", "beacon", "bison", "blaze", "bloom", "bongo", "boulder", "bramble", "brave",
+	"breeze", "brook", "bubble", "cactus", "camel", "candle", "canyon", "carrot", "cedar", "cello",
+	"chalk", "cherry", "cider", "clover", "cobalt", "comet", "copper", "coral", "cosmic", "cotton",
+	"crane", "crater", "cricket", "crystal", "cypress", "daisy", "dawn", "delta", "desert", "dingo",
+	"dolphin", "dragon", "drift", "dune", "eagle", "ember", "falcon", "fern", "fiddle", "fig",
+	"finch", "fjord", "flame", "flint", "forest", "fossil", "fox", "frost", "galaxy", "garnet",
+	"gecko", "ginger", "glacier", "globe", "gold", "granite", "grape", "gravel", "grove", "gull",
+	"harbor", "hazel", "heron", "hickory", "honey", "horizon", "husky", "iceberg", "iris", "island",
+	"ivory", "jade", "jaguar", "jasmine", "jelly", "jungle", "kayak", "kelp", "kettle", "kiwi",
+	"koala", "lagoon", "lantern", "lava", "lemon", "lilac", "lily", "lime", "lizard", "llama",
+	"lotus", "lunar", "lynx", "magnet", "mango", "maple", "marble", "meadow", "melon", "mint",
+	"mocha", "moose", "moss", "nebula", "nectar", "nutmeg", "oasis", "ocean", "olive", "onyx",
+	"orbit", "orchid", "osprey", "otter", "owl", "oyster", "panda", "papaya", "parrot", "peach",
+	"pebble", "pepper", "pine", "pixel", "plum", "polar", "pond", "poppy", "prairie", "puffin",
+	"quartz", "quill", "rabbit", "radar", "raven", "reef", "ridge", "river", "robin", "rocket",
+	"ruby", "saffron", "sage", "salmon", "sapphire", "savanna", "scarlet", "shadow", "shell",
+	"sierra", "silver", "sky", "slate", "sparrow", "spruce", "squid", "star", "stone", "storm",
+	"summit", "sunset", "swan", "tango", "thistle", "thunder", "tiger", "timber", "topaz", "torch",
+	"tulip", "tundra", "turtle", "umber", "valley", "velvet", "violet", "volcano", "walnut", "walrus",
+	"willow", "window", "winter", "wolf", "yak", "zebra", "zinc",
+}
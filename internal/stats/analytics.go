@@ -0,0 +1,155 @@
+package stats
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/jponc/estimatex-serverless/internal/types"
+)
+
+// Session is a room with its revealed rounds
+type Session struct {
+	Room   types.Room
+	Rounds []types.Round
+}
+
+// ComputeAnalytics compares every vote of the sessions' finalized rounds with the round's final
+// estimate, overall and per participant, and measures how many stories were estimated per hour
+func ComputeAnalytics(sessions []Session) types.Analytics {
+	a := types.Analytics{
+		Sessions:     len(sessions),
+		Participants: []types.ParticipantAccuracy{},
+	}
+
+	overall := accuracy{}
+	participants := map[string]*accuracy{}
+	names := map[string]types.ParticipantAccuracy{}
+	var hours float64
+
+	for _, session := range sessions {
+		var start, end time.Time
+		stories := map[string]bool{}
+
+		for _, round := range session.Rounds {
+			if round.FinalEstimate == "" {
+				continue
+			}
+
+			a.Rounds++
+
+			if start.IsZero() || round.StartedAt.Before(start) {
+				start = round.StartedAt
+			}
+			if round.FinalizedAt.After(end) {
+				end = round.FinalizedAt
+			}
+
+			// Rounds voted without a story each estimate something, re-votes of a story don't
+			if round.StoryID == "" {
+				a.Velocity.Stories++
+			} else if !stories[round.StoryID] {
+				stories[round.StoryID] = true
+				a.Velocity.Stories++
+			}
+
+			final, finalErr := strconv.ParseFloat(round.FinalEstimate, 64)
+
+			for _, p := range round.Stats.Participants {
+				key := "name:" + p.Name
+				if p.UserID != "" {
+					key = "user:" + p.UserID
+				}
+
+				if participants[key] == nil {
+					participants[key] = &accuracy{}
+				}
+				// The latest name a signed in user voted with is the one shown
+				names[key] = types.ParticipantAccuracy{Name: p.Name, UserID: p.UserID}
+
+				vote, voteErr := strconv.ParseFloat(p.Vote, 64)
+				numeric := finalErr == nil && voteErr == nil
+
+				overall.add(p.Vote == round.FinalEstimate, numeric, vote-final)
+				participants[key].add(p.Vote == round.FinalEstimate, numeric, vote-final)
+			}
+		}
+
+		if !start.IsZero() && end.After(start) {
+			hours += end.Sub(start).Hours()
+		}
+	}
+
+	a.Accuracy = overall.result()
+
+	for key, acc := range participants {
+		p := names[key]
+		p.EstimationAccuracy = acc.result()
+		a.Participants = append(a.Participants, p)
+	}
+
+	sort.Slice(a.Participants, func(i, j int) bool {
+		if a.Participants[i].Name != a.Participants[j].Name {
+			return a.Participants[i].Name < a.Participants[j].Name
+		}
+		return a.Participants[i].UserID < a.Participants[j].UserID
+	})
+
+	a.Velocity.Hours = hours
+	if hours > 0 {
+		a.Velocity.StoriesPerHour = float64(a.Velocity.Stories) / hours
+	}
+
+	return a
+}
+
+// accuracy accumulates votes for an EstimationAccuracy
+type accuracy struct {
+	votes, matches, numericVotes, over, under int
+	absSum, sum                               float64
+}
+
+// add counts a vote, deviation is the vote minus the final estimate when both are numeric
+func (a *accuracy) add(match, numeric bool, deviation float64) {
+	a.votes++
+	if match {
+		a.matches++
+	}
+
+	if !numeric {
+		return
+	}
+
+	a.numericVotes++
+	a.absSum += math.Abs(deviation)
+	a.sum += deviation
+
+	switch {
+	case deviation > 0:
+		a.over++
+	case deviation < 0:
+		a.under++
+	}
+}
+
+func (a *accuracy) result() types.EstimationAccuracy {
+	r := types.EstimationAccuracy{
+		Votes:          a.votes,
+		Matches:        a.matches,
+		NumericVotes:   a.numericVotes,
+		Overestimates:  a.over,
+		Underestimates: a.under,
+	}
+
+	if a.votes > 0 {
+		r.MatchRate = float64(a.matches) / float64(a.votes)
+	}
+
+	if a.numericVotes > 0 {
+		r.AverageDeviation = a.absSum / float64(a.numericVotes)
+		r.Bias = a.sum / float64(a.numericVotes)
+	}
+
+	return r
+}
@@ -0,0 +1,102 @@
+package stats
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/jponc/estimatex-serverless/internal/types"
+)
+
+// ComputeRound summarises the participants' votes for a round that started at roundStartedAt.
+// Participants who haven't voted are left out.
+func ComputeRound(roundStartedAt time.Time, participants []types.Participant) types.RoundStats {
+	s := types.RoundStats{
+		Participants: []types.ParticipantStats{},
+	}
+
+	sum := 0.0
+	s.Min = math.Inf(1)
+	s.Max = math.Inf(-1)
+
+	for _, p := range participants {
+		if p.LatestVote == "" {
+			continue
+		}
+
+		firstVotedAt := p.VotedAt
+		if len(p.PreviousVotes) > 0 {
+			firstVotedAt = p.PreviousVotes[0].VotedAt
+		}
+
+		s.VoteCount++
+		s.ChangedVotes += len(p.PreviousVotes)
+		s.Participants = append(s.Participants, types.ParticipantStats{
+			Name:                   p.Name,
+			Vote:                   p.LatestVote,
+			Comment:                p.Comment,
+			Changes:                len(p.PreviousVotes),
+			TimeToFirstVoteSeconds: secondsSince(roundStartedAt, firstVotedAt),
+			TimeToFinalVoteSeconds: secondsSince(roundStartedAt, p.VotedAt),
+			UserID:                 p.UserID,
+		})
+
+		v, err := strconv.ParseFloat(p.LatestVote, 64)
+		if err != nil {
+			continue
+		}
+
+		s.NumericVoteCount++
+		sum += v
+		s.Min = math.Min(s.Min, v)
+		s.Max = math.Max(s.Max, v)
+	}
+
+	if s.NumericVoteCount == 0 {
+		s.Min = 0
+		s.Max = 0
+	} else {
+		s.Average = sum / float64(s.NumericVoteCount)
+	}
+
+	sort.Slice(s.Participants, func(i, j int) bool {
+		return s.Participants[i].TimeToFinalVoteSeconds < s.Participants[j].TimeToFinalVoteSeconds
+	})
+
+	return s
+}
+
+func secondsSince(start, t time.Time) float64 {
+	if start.IsZero() || t.IsZero() || t.Before(start) {
+		return 0
+	}
+
+	return t.Sub(start).Seconds()
+}
+
+// CompareRounds compares a re-vote with the round it re-votes
+func CompareRounds(parent types.Round, current types.RoundStats) types.RoundComparison {
+	previousVotes := map[string]string{}
+	for _, p := range parent.Stats.Participants {
+		previousVotes[p.Name] = p.Vote
+	}
+
+	changed := []string{}
+	for _, p := range current.Participants {
+		if v, ok := previousVotes[p.Name]; ok && v != p.Vote {
+			changed = append(changed, p.Name)
+		}
+	}
+	sort.Strings(changed)
+
+	return types.RoundComparison{
+		ParentRound:         parent.Number,
+		PreviousSpread:      parent.Stats.Spread(),
+		Spread:              current.Spread(),
+		PreviousAverage:     parent.Stats.Average,
+		Average:             current.Average,
+		Converged:           current.Spread() < parent.Stats.Spread(),
+		ChangedParticipants: changed,
+	}
+}
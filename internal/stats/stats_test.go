@@ -0,0 +1,176 @@
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jponc/estimatex-serverless/internal/types"
+)
+
+func TestComputeRound(t *testing.T) {
+	start := time.Date(2021, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	vote := func(name, value string, after time.Duration) types.Participant {
+		return types.Participant{Name: name, LatestVote: value, VotedAt: start.Add(after)}
+	}
+
+	tests := []struct {
+		name         string
+		participants []types.Participant
+		voteCount    int
+		numericCount int
+		average      float64
+		min          float64
+		max          float64
+	}{
+		{
+			name:         "no participants",
+			participants: nil,
+		},
+		{
+			name:         "nobody voted",
+			participants: []types.Participant{{Name: "Ana"}, {Name: "Ben"}},
+		},
+		{
+			name: "numeric votes",
+			participants: []types.Participant{
+				vote("Ana", "3", time.Second),
+				vote("Ben", "8", 2*time.Second),
+				vote("Cat", "0.5", 3*time.Second),
+			},
+			voteCount:    3,
+			numericCount: 3,
+			average:      11.5 / 3,
+			min:          0.5,
+			max:          8,
+		},
+		{
+			name: "? and coffee count as votes but not towards the numbers",
+			participants: []types.Participant{
+				vote("Ana", "5", time.Second),
+				vote("Ben", "?", 2*time.Second),
+				vote("Cat", "coffee", 3*time.Second),
+				vote("Dan", "13", 4*time.Second),
+			},
+			voteCount:    4,
+			numericCount: 2,
+			average:      9,
+			min:          5,
+			max:          13,
+		},
+		{
+			name: "only ? and coffee",
+			participants: []types.Participant{
+				vote("Ana", "?", time.Second),
+				vote("Ben", "coffee", 2*time.Second),
+			},
+			voteCount: 2,
+		},
+		{
+			name: "everyone agrees",
+			participants: []types.Participant{
+				vote("Ana", "5", time.Second),
+				vote("Ben", "5", 2*time.Second),
+				{Name: "Cat"},
+			},
+			voteCount:    2,
+			numericCount: 2,
+			average:      5,
+			min:          5,
+			max:          5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := ComputeRound(start, tt.participants)
+
+			if s.VoteCount != tt.voteCount || s.NumericVoteCount != tt.numericCount {
+				t.Errorf("counts = %d/%d, want %d/%d", s.VoteCount, s.NumericVoteCount, tt.voteCount, tt.numericCount)
+			}
+			if s.Average != tt.average || s.Min != tt.min || s.Max != tt.max {
+				t.Errorf("average/min/max = %v/%v/%v, want %v/%v/%v", s.Average, s.Min, s.Max, tt.average, tt.min, tt.max)
+			}
+			if len(s.Participants) != tt.voteCount {
+				t.Errorf("got %d participant stats, want %d", len(s.Participants), tt.voteCount)
+			}
+		})
+	}
+}
+
+func TestComputeRoundTiming(t *testing.T) {
+	start := time.Date(2021, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	participants := []types.Participant{
+		{
+			Name:       "Ana",
+			LatestVote: "8",
+			VotedAt:    start.Add(30 * time.Second),
+			PreviousVotes: []types.Vote{
+				{Value: "3", VotedAt: start.Add(5 * time.Second)},
+				{Value: "5", VotedAt: start.Add(20 * time.Second)},
+			},
+		},
+		// Votes kept from before the round started don't give negative times
+		{Name: "Ben", LatestVote: "?", VotedAt: start.Add(-time.Minute)},
+	}
+
+	s := ComputeRound(start, participants)
+
+	if s.ChangedVotes != 2 {
+		t.Errorf("changed votes = %d, want 2", s.ChangedVotes)
+	}
+
+	// Participants are sorted by when they settled on their vote
+	want := []types.ParticipantStats{
+		{Name: "Ben", Vote: "?"},
+		{Name: "Ana", Vote: "8", Changes: 2, TimeToFirstVoteSeconds: 5, TimeToFinalVoteSeconds: 30},
+	}
+
+	if len(s.Participants) != len(want) {
+		t.Fatalf("got %d participant stats, want %d", len(s.Participants), len(want))
+	}
+	for i := range want {
+		if s.Participants[i] != want[i] {
+			t.Errorf("participant %d = %+v, want %+v", i, s.Participants[i], want[i])
+		}
+	}
+}
+
+func TestCompareRounds(t *testing.T) {
+	parent := types.Round{
+		Number: 1,
+		Stats: types.RoundStats{
+			Average: 6,
+			Min:     2,
+			Max:     13,
+			Participants: []types.ParticipantStats{
+				{Name: "Ana", Vote: "2"},
+				{Name: "Ben", Vote: "13"},
+				{Name: "Cat", Vote: "?"},
+			},
+		},
+	}
+
+	current := types.RoundStats{
+		Average: 6.5,
+		Min:     5,
+		Max:     8,
+		Participants: []types.ParticipantStats{
+			{Name: "Ben", Vote: "8"},
+			{Name: "Ana", Vote: "5"},
+			{Name: "Cat", Vote: "?"},
+			// Joined for the re-vote
+			{Name: "Dan", Vote: "5"},
+		},
+	}
+
+	c := CompareRounds(parent, current)
+
+	if c.ParentRound != 1 || c.PreviousSpread != 11 || c.Spread != 3 || !c.Converged {
+		t.Errorf("comparison = %+v, want round 1 converging from 11 to 3", c)
+	}
+	if len(c.ChangedParticipants) != 2 || c.ChangedParticipants[0] != "Ana" || c.ChangedParticipants[1] != "Ben" {
+		t.Errorf("changed participants = %v, want [Ana Ben]", c.ChangedParticipants)
+	}
+}
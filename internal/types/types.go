@@ -4,10 +4,54 @@ import (
 	"time"
 )
 
+// DefaultDeck is the deck of cards rooms vote with unless they're hosted with their own
+var DefaultDeck = []string{"0", "0.5", "1", "2", "3", "5", "8", "13", "20", "40", "100", "?", "coffee"}
+
 type Room struct {
-	ID        string    `json:"id"`
-	CreatedAt time.Time `json:"created_at"`
-	EndedAt   time.Time `json:"ended_at"`
+	ID             string    `json:"id"`
+	CreatedAt      time.Time `json:"created_at"`
+	EndedAt        time.Time `json:"ended_at"`
+	Sequence       int64     `json:"sequence"`
+	RoundStartedAt time.Time `json:"round_started_at"`
+	// Round numbers the room's rounds from 1, ParentRound is the round the current one re-votes
+	Round       int      `json:"round"`
+	ParentRound int      `json:"parent_round,omitempty"`
+	Deck        []string `json:"deck"`
+	// CurrentStoryID is the story being estimated, StoryQueue the IDs of the stories up next in order
+	CurrentStoryID string   `json:"current_story_id,omitempty"`
+	StoryQueue     []string `json:"story_queue"`
+	// TeamSlug is the team the room is a session of, empty for one-off rooms
+	TeamSlug string `json:"team_slug,omitempty"`
+}
+
+// Cards returns the room's deck, rooms hosted without one use DefaultDeck
+func (r *Room) Cards() []string {
+	if len(r.Deck) == 0 {
+		return DefaultDeck
+	}
+
+	return r.Deck
+}
+
+// CurrentRound returns the number of the round being voted on, rooms hosted before rounds were
+// numbered are on their first
+func (r *Room) CurrentRound() int {
+	if r.Round < 1 {
+		return 1
+	}
+
+	return r.Round
+}
+
+// HasCard reports whether card is in the room's deck
+func (r *Room) HasCard(card string) bool {
+	for _, c := range r.Cards() {
+		if c == card {
+			return true
+		}
+	}
+
+	return false
 }
 
 type Participant struct {
@@ -15,7 +59,301 @@ type Participant struct {
 	Name       string    `json:"name"`
 	IsAdmin    bool      `json:"is_admin"`
 	LatestVote string    `json:"latest_vote"`
-	CreatedAt  time.Time `json:"created_at"`
+	VotedAt    time.Time `json:"voted_at"`
+	// Comment is the rationale given with LatestVote, only shared once votes are revealed
+	Comment string `json:"comment,omitempty"`
+	// PreviousVotes are the votes the participant changed away from in the current round, oldest first
+	PreviousVotes []Vote    `json:"previous_votes"`
+	CreatedAt     time.Time `json:"created_at"`
+	// UserID is the signed in user behind the participant, empty for anonymous participants
+	UserID string `json:"user_id,omitempty"`
 }
 
 type ParticipantArr []Participant
+
+type Vote struct {
+	Value   string    `json:"value"`
+	VotedAt time.Time `json:"voted_at"`
+	Comment string    `json:"comment,omitempty"`
+}
+
+// RecordVote sets the participant's vote and comment, keeping the vote it replaces for the round.
+// Voting the same value again only updates the comment.
+func (p *Participant) RecordVote(vote, comment string, at time.Time) {
+	if p.LatestVote == vote {
+		p.Comment = comment
+		return
+	}
+
+	if p.LatestVote != "" {
+		p.PreviousVotes = append(p.PreviousVotes, Vote{
+			Value:   p.LatestVote,
+			VotedAt: p.VotedAt,
+			Comment: p.Comment,
+		})
+	}
+
+	p.LatestVote = vote
+	p.VotedAt = at
+	p.Comment = comment
+}
+
+// ResetVote clears the participant's vote and history for a new round
+func (p *Participant) ResetVote() {
+	p.LatestVote = ""
+	p.VotedAt = time.Time{}
+	p.Comment = ""
+	p.PreviousVotes = nil
+}
+
+// HideComments clears the comments of the participant's votes, they're only shared on reveal
+func (p *Participant) HideComments() {
+	p.Comment = ""
+	for i := range p.PreviousVotes {
+		p.PreviousVotes[i].Comment = ""
+	}
+}
+
+// RoundStats summarises the votes of a round when they're revealed
+type RoundStats struct {
+	VoteCount    int `json:"vote_count"`
+	ChangedVotes int `json:"changed_votes"`
+	// Average, Min and Max only consider numeric votes
+	NumericVoteCount int                `json:"numeric_vote_count"`
+	Average          float64            `json:"average"`
+	Min              float64            `json:"min"`
+	Max              float64            `json:"max"`
+	Participants     []ParticipantStats `json:"participants"`
+}
+
+// Spread is the difference between the highest and lowest numeric vote
+func (s RoundStats) Spread() float64 {
+	return s.Max - s.Min
+}
+
+// Round is a revealed round kept for the room's history
+type Round struct {
+	RoomID      string     `json:"room_id"`
+	Number      int        `json:"number"`
+	ParentRound int        `json:"parent_round,omitempty"`
+	StartedAt   time.Time  `json:"started_at"`
+	RevealedAt  time.Time  `json:"revealed_at"`
+	StoryID     string     `json:"story_id,omitempty"`
+	Stats       RoundStats `json:"stats"`
+	// Comparison is set when the round re-votes ParentRound
+	Comparison *RoundComparison `json:"comparison,omitempty"`
+	// FinalEstimate is the value the host settled on, a card from the deck unless it's an override
+	FinalEstimate         string    `json:"final_estimate,omitempty"`
+	FinalEstimateOverride bool      `json:"final_estimate_override,omitempty"`
+	FinalizedAt           time.Time `json:"finalized_at"`
+	// TeamSlug is copied from the room so the round shows up in the team's history
+	TeamSlug string `json:"team_slug,omitempty"`
+}
+
+// RoundComparison shows how votes moved between a round and its re-vote
+type RoundComparison struct {
+	ParentRound     int     `json:"parent_round"`
+	PreviousSpread  float64 `json:"previous_spread"`
+	Spread          float64 `json:"spread"`
+	PreviousAverage float64 `json:"previous_average"`
+	Average         float64 `json:"average"`
+	// Converged is true when the spread narrowed
+	Converged bool `json:"converged"`
+	// ChangedParticipants are the participants who voted differently in the re-vote
+	ChangedParticipants []string `json:"changed_participants"`
+}
+
+// Story is a backlog item the room estimates
+type Story struct {
+	ID          string    `json:"id"`
+	RoomID      string    `json:"room_id"`
+	Title       string    `json:"title"`
+	Description string    `json:"description,omitempty"`
+	ExternalKey string    `json:"external_key,omitempty"`
+	URL         string    `json:"url,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type ParticipantStats struct {
+	Name                   string  `json:"name"`
+	Vote                   string  `json:"vote"`
+	Comment                string  `json:"comment,omitempty"`
+	Changes                int     `json:"changes"`
+	TimeToFirstVoteSeconds float64 `json:"time_to_first_vote_seconds"`
+	TimeToFinalVoteSeconds float64 `json:"time_to_final_vote_seconds"`
+	// UserID is set for participants who voted signed in
+	UserID string `json:"user_id,omitempty"`
+}
+
+// Trackers a room can be connected to
+const (
+	TrackerJira   = "jira"
+	TrackerGitHub = "github"
+)
+
+// TrackerIntegration connects a room to an issue tracker, its credentials are kept apart in
+// Parameter Store
+type TrackerIntegration struct {
+	Kind string `json:"kind"`
+	// BaseURL is the tracker's API, e.g. https://acme.atlassian.net, empty for GitHub's public API
+	BaseURL string `json:"base_url,omitempty"`
+	// Project is the Jira project key or the GitHub owner/repo issues are searched in
+	Project string `json:"project"`
+	// PointsField is the Jira custom field holding story points, unused for GitHub
+	PointsField string    `json:"points_field,omitempty"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// TrackerIssue is an issue found in a tracker, ready to be added as a story
+type TrackerIssue struct {
+	Key         string `json:"key"`
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	URL         string `json:"url"`
+}
+
+// Webhook is an HTTPS endpoint the room's events are delivered to, signed with a secret only shown
+// when it's registered
+type Webhook struct {
+	ID  string `json:"id"`
+	URL string `json:"url"`
+	// EventTypes limits the delivered events, empty delivers every event
+	EventTypes []string  `json:"event_types"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// WebhookDelivery is the outcome of delivering an event to a webhook, after any retries
+type WebhookDelivery struct {
+	ID        string `json:"id"`
+	WebhookID string `json:"webhook_id"`
+	EventID   string `json:"event_id"`
+	EventType string `json:"event_type"`
+	Attempts  int    `json:"attempts"`
+	Succeeded bool   `json:"succeeded"`
+	// StatusCode is the last response's status, 0 when no response was received
+	StatusCode  int       `json:"status_code"`
+	Error       string    `json:"error,omitempty"`
+	DurationMs  int64     `json:"duration_ms"`
+	DeliveredAt time.Time `json:"delivered_at"`
+}
+
+// Chat apps round results can be posted to
+const (
+	ChatSlack = "slack"
+	ChatTeams = "teams"
+)
+
+// ChatIntegration posts the room's round results to a Slack or Teams channel, its incoming webhook
+// URL holds a token so it's kept apart and never returned
+type ChatIntegration struct {
+	Kind      string    `json:"kind"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Roles a team member joins the team's sessions with
+const (
+	RoleAdmin = "admin"
+	RoleVoter = "voter"
+)
+
+// Team owns a stable slug its recurring sessions are joined with, and the defaults each new
+// session starts from
+type Team struct {
+	Slug string `json:"slug"`
+	Name string `json:"name"`
+	// Deck is the deck sessions vote with unless the host picks another, empty means DefaultDeck
+	Deck []string `json:"deck"`
+	// DefaultRole is the role of people joining who aren't members
+	DefaultRole string       `json:"default_role"`
+	Members     []TeamMember `json:"members"`
+	// CurrentRoomID is the session joining the team's slug leads to, empty before the first one
+	CurrentRoomID string    `json:"current_room_id,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+type TeamMember struct {
+	Name string `json:"name"`
+	Role string `json:"role"`
+	// UserID links the member to a signed in user, who then gets their role without the team key
+	UserID string `json:"user_id,omitempty"`
+}
+
+// RoleOf returns the role of the member called name, DefaultRole when there's none
+func (t *Team) RoleOf(name string) string {
+	for _, m := range t.Members {
+		if m.Name == name {
+			return m.Role
+		}
+	}
+
+	if t.DefaultRole == "" {
+		return RoleVoter
+	}
+
+	return t.DefaultRole
+}
+
+// MemberByUser returns the member linked to userID, nil when there's none
+func (t *Team) MemberByUser(userID string) *TeamMember {
+	for i := range t.Members {
+		if t.Members[i].UserID != "" && t.Members[i].UserID == userID {
+			return &t.Members[i]
+		}
+	}
+
+	return nil
+}
+
+// User is someone signed in through the identity provider, they keep the same ID across rooms
+// and teams
+type User struct {
+	ID string `json:"id"`
+	// Issuer and Subject identify the user at the identity provider
+	Issuer      string    `json:"issuer"`
+	Subject     string    `json:"subject"`
+	Email       string    `json:"email,omitempty"`
+	Name        string    `json:"name,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	LastLoginAt time.Time `json:"last_login_at"`
+}
+
+// Analytics measures how well a team's estimates held up over a page of its sessions
+type Analytics struct {
+	Sessions int `json:"sessions"`
+	// Rounds counts the finalized rounds votes are compared against
+	Rounds       int                   `json:"rounds"`
+	Accuracy     EstimationAccuracy    `json:"accuracy"`
+	Participants []ParticipantAccuracy `json:"participants"`
+	Velocity     EstimationVelocity    `json:"velocity"`
+}
+
+// EstimationAccuracy compares votes with the final estimate of their round
+type EstimationAccuracy struct {
+	Votes     int     `json:"votes"`
+	Matches   int     `json:"matches"`
+	MatchRate float64 `json:"match_rate"`
+	// The deviations only consider numeric votes on rounds with a numeric final estimate
+	NumericVotes int `json:"numeric_votes"`
+	// AverageDeviation is the mean distance from the final estimate, Bias the mean signed
+	// difference, positive when votes tend to overestimate
+	AverageDeviation float64 `json:"average_deviation"`
+	Bias             float64 `json:"bias"`
+	Overestimates    int     `json:"overestimates"`
+	Underestimates   int     `json:"underestimates"`
+}
+
+// ParticipantAccuracy is the accuracy of one person's votes, signed in participants are tracked by
+// user ID and anonymous ones by name
+type ParticipantAccuracy struct {
+	Name   string `json:"name"`
+	UserID string `json:"user_id,omitempty"`
+	EstimationAccuracy
+}
+
+// EstimationVelocity is how quickly stories were estimated while sessions were running
+type EstimationVelocity struct {
+	Stories        int     `json:"stories"`
+	Hours          float64 `json:"hours"`
+	StoriesPerHour float64 `json:"stories_per_hour"`
+}
@@ -0,0 +1,111 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/jponc/estimatex-serverless/api/schema"
+	"github.com/jponc/estimatex-serverless/internal/repository/ddbrepository"
+	"github.com/jponc/estimatex-serverless/pkg/logger"
+	log "github.com/sirupsen/logrus"
+)
+
+// HandlerFunc handles a single room event
+type HandlerFunc func(ctx context.Context, event schema.Event) error
+
+// Router dispatches room events to the handler registered for their type
+type Router struct {
+	consumer      string
+	ddbrepository *ddbrepository.Repository
+	handlers      map[string]HandlerFunc
+}
+
+// NewRouter instantiates an empty router. When ddbrepository is set, events already
+// handled by consumer are skipped, since SNS delivers at-least-once.
+func NewRouter(consumer string, ddbrepository *ddbrepository.Repository) *Router {
+	return &Router{
+		consumer:      consumer,
+		ddbrepository: ddbrepository,
+		handlers:      map[string]HandlerFunc{},
+	}
+}
+
+// Register sets the handler for eventType, replacing any existing one
+func (r *Router) Register(eventType string, handler HandlerFunc) {
+	r.handlers[eventType] = handler
+}
+
+// Route unmarshals every SNS record into an Event and dispatches it. Events without a
+// registered handler are skipped so new event types can be published before they're handled.
+func (r *Router) Route(ctx context.Context, snsEvent events.SNSEvent) error {
+	for _, record := range snsEvent.Records {
+		var event schema.Event
+		err := json.Unmarshal([]byte(record.SNS.Message), &event)
+		if err != nil {
+			return fmt.Errorf("unable to unmarshal event: %v", err)
+		}
+
+		eventCtx := eventContext(ctx, record, event)
+		l := logger.FromContext(eventCtx)
+
+		handler, ok := r.handlers[event.Type]
+		if !ok {
+			l.Warn("no handler registered for event type")
+			continue
+		}
+
+		if r.ddbrepository != nil {
+			err = r.ddbrepository.MarkEventProcessed(eventCtx, r.consumer, event)
+			if errors.Is(err, ddbrepository.ErrAlreadyExists) {
+				l.Info("skipping duplicate event")
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("failed to mark event (%s) as processed: %v", event.ID, err)
+			}
+		}
+
+		err = handler(eventCtx, event)
+		if err != nil {
+			r.unmark(eventCtx, event)
+			return fmt.Errorf("failed to handle %s event (%s): %v", event.Type, event.ID, err)
+		}
+
+		l.Info("handled event")
+	}
+
+	return nil
+}
+
+// unmark releases the processed marker so the redelivered event is handled again
+func (r *Router) unmark(ctx context.Context, event schema.Event) {
+	if r.ddbrepository == nil {
+		return
+	}
+
+	err := r.ddbrepository.UnmarkEventProcessed(ctx, r.consumer, event)
+	if err != nil {
+		logger.FromContext(ctx).Errorf("failed to unmark event: %v", err)
+	}
+}
+
+// eventContext attaches the event to the context's logger. The correlation ID comes from the SNS
+// message attribute, falling back to the one on the event.
+func eventContext(ctx context.Context, record events.SNSEventRecord, event schema.Event) context.Context {
+	correlationID := event.CorrelationID
+	if attr, ok := record.SNS.MessageAttributes[schema.CorrelationIDAttribute].(map[string]interface{}); ok {
+		if v, ok := attr["Value"].(string); ok && v != "" {
+			correlationID = v
+		}
+	}
+
+	ctx = logger.WithCorrelationID(ctx, correlationID)
+	return logger.WithFields(ctx, log.Fields{
+		logger.FieldRoomID:    event.RoomID,
+		logger.FieldEventID:   event.ID,
+		logger.FieldEventType: event.Type,
+	})
+}
@@ -2,126 +2,220 @@ package webhooks
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-
-	log "github.com/sirupsen/logrus"
+	"time"
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/jponc/estimatex-serverless/api/schema"
+	"github.com/jponc/estimatex-serverless/internal/repository/ddbrepository"
+	"github.com/jponc/estimatex-serverless/pkg/metrics"
 	"github.com/jponc/estimatex-serverless/pkg/pusher"
 )
 
 type Service struct {
 	pusherClient *pusher.Client
+	metrics      *metrics.Recorder
+	router       *Router
 }
 
-// NewService instantiates a new service
-func NewService(pusherClient *pusher.Client) *Service {
-	return &Service{
+// pusherConsumer identifies the Pusher handlers when deduplicating events
+const pusherConsumer = "PublishToPusher"
+
+const (
+	metricPusherPublished      = "PusherPublished"
+	metricPusherPublishFailed  = "PusherPublishFailures"
+	metricPusherPublishLatency = "PusherPublishLatency"
+)
+
+// NewService instantiates a new service and registers the Pusher handlers
+func NewService(pusherClient *pusher.Client, ddbrepository *ddbrepository.Repository, metrics *metrics.Recorder) *Service {
+	s := &Service{
 		pusherClient: pusherClient,
+		metrics:      metrics,
+		router:       NewRouter(pusherConsumer, ddbrepository),
 	}
+
+	s.router.Register(schema.ParticipantJoined, s.publishParticipantJoined)
+	s.router.Register(schema.ParticipantVoted, s.publishParticipantVoted)
+	s.router.Register(schema.RevealVotes, s.publishRevealVotes)
+	s.router.Register(schema.ResetVotes, s.publishResetVotes)
+	s.router.Register(schema.ParticipantKicked, s.publishParticipantKicked)
+	s.router.Register(schema.ParticipantRenamed, s.publishParticipantRenamed)
+	s.router.Register(schema.EstimateFinalized, s.publishEstimateFinalized)
+	s.router.Register(schema.StoryQueueChanged, s.publishStoryQueueChanged)
+	s.router.Register(schema.StoryChanged, s.publishStoryChanged)
+
+	return s
+}
+
+// Register adds a handler for a new event type
+func (s *Service) Register(eventType string, handler HandlerFunc) {
+	s.router.Register(eventType, handler)
 }
 
-func (s *Service) PublishToPusherParticipantJoined(ctx context.Context, snsEvent events.SNSEvent) {
-	snsMsg := snsEvent.Records[0].SNS.Message
+// PublishToPusher routes room events from SNS to their registered handlers
+func (s *Service) PublishToPusher(ctx context.Context, snsEvent events.SNSEvent) error {
+	return s.router.Route(ctx, snsEvent)
+}
 
+func (s *Service) publishParticipantJoined(ctx context.Context, event schema.Event) error {
 	var msg schema.ParticipantJoinedMessage
-	err := json.Unmarshal([]byte(snsMsg), &msg)
+	err := event.UnmarshalPayload(&msg)
 	if err != nil {
-		log.Fatalf("unable to unarmarshal message: %v", err)
+		return fmt.Errorf("unable to unmarshal payload: %v", err)
 	}
 
-	if s.pusherClient == nil {
-		log.Fatalf("pusherClient not defined")
+	data := map[string]interface{}{
+		"participant_name": msg.ParticipantName,
+	}
+
+	return s.trigger(ctx, event, "participant-joined", data)
+}
+
+func (s *Service) publishParticipantVoted(ctx context.Context, event schema.Event) error {
+	var msg schema.ParticipantVotedMessage
+	err := event.UnmarshalPayload(&msg)
+	if err != nil {
+		return fmt.Errorf("unable to unmarshal payload: %v", err)
 	}
 
-	channel := fmt.Sprintf("room-%s", msg.RoomID)
-	event := "participant-joined"
-	data := map[string]string{
-		"room_id":          msg.RoomID,
+	data := map[string]interface{}{
 		"participant_name": msg.ParticipantName,
+		"vote":             msg.Vote,
 	}
 
-	err = s.pusherClient.Trigger(ctx, channel, event, data)
+	return s.trigger(ctx, event, "participant-voted", data)
+}
+
+func (s *Service) publishRevealVotes(ctx context.Context, event schema.Event) error {
+	var msg schema.RevealVotesMessage
+	err := event.UnmarshalPayload(&msg)
 	if err != nil {
-		log.Fatalf("failed to trigger push: %w", err)
+		return fmt.Errorf("unable to unmarshal payload: %v", err)
 	}
-}
 
-func (s *Service) PublishToPusherParticipantVoted(ctx context.Context, snsEvent events.SNSEvent) {
-	snsMsg := snsEvent.Records[0].SNS.Message
+	data := map[string]interface{}{
+		"round": msg.Round,
+		"stats": msg.Stats,
+	}
+	if msg.Comparison != nil {
+		data["comparison"] = msg.Comparison
+	}
 
-	var msg schema.ParticipantVotedMessage
-	err := json.Unmarshal([]byte(snsMsg), &msg)
+	return s.trigger(ctx, event, "reveal-votes", data)
+}
+
+func (s *Service) publishResetVotes(ctx context.Context, event schema.Event) error {
+	var msg schema.ResetVotesMessage
+	err := event.UnmarshalPayload(&msg)
 	if err != nil {
-		log.Fatalf("unable to unarmarshal message: %v", err)
+		return fmt.Errorf("unable to unmarshal payload: %v", err)
 	}
 
-	if s.pusherClient == nil {
-		log.Fatalf("pusherClient not defined")
+	data := map[string]interface{}{
+		"round":        msg.Round,
+		"parent_round": msg.ParentRound,
+	}
+
+	return s.trigger(ctx, event, "reset-votes", data)
+}
+
+func (s *Service) publishParticipantKicked(ctx context.Context, event schema.Event) error {
+	var msg schema.ParticipantKickedMessage
+	err := event.UnmarshalPayload(&msg)
+	if err != nil {
+		return fmt.Errorf("unable to unmarshal payload: %v", err)
 	}
 
-	channel := fmt.Sprintf("room-%s", msg.RoomID)
-	event := "participant-voted"
-	data := map[string]string{
-		"room_id":          msg.RoomID,
+	data := map[string]interface{}{
 		"participant_name": msg.ParticipantName,
-		"vote":             msg.Vote,
 	}
 
-	err = s.pusherClient.Trigger(ctx, channel, event, data)
+	return s.trigger(ctx, event, "participant-kicked", data)
+}
+
+func (s *Service) publishParticipantRenamed(ctx context.Context, event schema.Event) error {
+	var msg schema.ParticipantRenamedMessage
+	err := event.UnmarshalPayload(&msg)
 	if err != nil {
-		log.Fatalf("failed to trigger push: %w", err)
+		return fmt.Errorf("unable to unmarshal payload: %v", err)
 	}
-}
 
-func (s *Service) PublishToPusherResetVotes(ctx context.Context, snsEvent events.SNSEvent) {
-	snsMsg := snsEvent.Records[0].SNS.Message
+	data := map[string]interface{}{
+		"participant_name":     msg.ParticipantName,
+		"new_participant_name": msg.NewParticipantName,
+	}
 
-	var msg schema.ParticipantVotedMessage
-	err := json.Unmarshal([]byte(snsMsg), &msg)
+	return s.trigger(ctx, event, "participant-renamed", data)
+}
+
+func (s *Service) publishEstimateFinalized(ctx context.Context, event schema.Event) error {
+	var msg schema.EstimateFinalizedMessage
+	err := event.UnmarshalPayload(&msg)
 	if err != nil {
-		log.Fatalf("unable to unarmarshal message: %v", err)
+		return fmt.Errorf("unable to unmarshal payload: %v", err)
 	}
 
-	if s.pusherClient == nil {
-		log.Fatalf("pusherClient not defined")
+	data := map[string]interface{}{
+		"round":    msg.Round,
+		"estimate": msg.Estimate,
+		"override": msg.Override,
 	}
 
-	channel := fmt.Sprintf("room-%s", msg.RoomID)
-	event := "reset-votes"
-	data := map[string]string{
-		"room_id": msg.RoomID,
-	}
+	return s.trigger(ctx, event, "estimate-finalized", data)
+}
 
-	err = s.pusherClient.Trigger(ctx, channel, event, data)
+func (s *Service) publishStoryQueueChanged(ctx context.Context, event schema.Event) error {
+	var msg schema.StoryQueueChangedMessage
+	err := event.UnmarshalPayload(&msg)
 	if err != nil {
-		log.Fatalf("failed to trigger push: %w", err)
+		return fmt.Errorf("unable to unmarshal payload: %v", err)
 	}
-}
 
-func (s *Service) PublishToPusherRevealVotes(ctx context.Context, snsEvent events.SNSEvent) {
-	snsMsg := snsEvent.Records[0].SNS.Message
+	data := map[string]interface{}{
+		"story_ids": msg.StoryIDs,
+	}
 
-	var msg schema.ParticipantVotedMessage
-	err := json.Unmarshal([]byte(snsMsg), &msg)
+	return s.trigger(ctx, event, "story-queue-changed", data)
+}
+
+func (s *Service) publishStoryChanged(ctx context.Context, event schema.Event) error {
+	var msg schema.StoryChangedMessage
+	err := event.UnmarshalPayload(&msg)
 	if err != nil {
-		log.Fatalf("unable to unarmarshal message: %v", err)
+		return fmt.Errorf("unable to unmarshal payload: %v", err)
 	}
 
-	if s.pusherClient == nil {
-		log.Fatalf("pusherClient not defined")
+	data := map[string]interface{}{
+		"story": msg.Story,
+		"round": msg.Round,
 	}
 
-	channel := fmt.Sprintf("room-%s", msg.RoomID)
-	event := "reveal-votes"
-	data := map[string]string{
-		"room_id": msg.RoomID,
+	return s.trigger(ctx, event, "story-changed", data)
+}
+
+// trigger pushes data to the room channel along with the event ID and sequence so
+// clients can drop duplicates and detect gaps
+func (s *Service) trigger(ctx context.Context, event schema.Event, pusherEvent string, data map[string]interface{}) error {
+	if s.pusherClient == nil {
+		return fmt.Errorf("pusherClient not defined")
 	}
 
-	err = s.pusherClient.Trigger(ctx, channel, event, data)
+	data["room_id"] = event.RoomID
+	data["event_id"] = event.ID
+	data["sequence"] = event.Sequence
+
+	channel := fmt.Sprintf("room-%s", event.RoomID)
+
+	start := time.Now()
+	err := s.pusherClient.Trigger(ctx, channel, pusherEvent, data)
+	latency := metrics.Duration(metricPusherPublishLatency, time.Since(start))
 	if err != nil {
-		log.Fatalf("failed to trigger push: %w", err)
+		s.metrics.Emit(ctx, metrics.Count(metricPusherPublishFailed, 1), latency)
+		return fmt.Errorf("failed to trigger push: %v", err)
 	}
+
+	s.metrics.Emit(ctx, metrics.Count(metricPusherPublished, 1), latency)
+
+	return nil
 }
@@ -44,10 +44,22 @@ func (c *Client) PutItem(ctx context.Context, input *dynamodb.PutItemInput) (*dy
 	return c.dynamodbClient.PutItemWithContext(ctx, input)
 }
 
+func (c *Client) UpdateItem(ctx context.Context, input *dynamodb.UpdateItemInput) (*dynamodb.UpdateItemOutput, error) {
+	return c.dynamodbClient.UpdateItemWithContext(ctx, input)
+}
+
+func (c *Client) DeleteItem(ctx context.Context, input *dynamodb.DeleteItemInput) (*dynamodb.DeleteItemOutput, error) {
+	return c.dynamodbClient.DeleteItemWithContext(ctx, input)
+}
+
 func (c *Client) BatchWriteItem(ctx context.Context, input *dynamodb.BatchWriteItemInput) (*dynamodb.BatchWriteItemOutput, error) {
 	return c.dynamodbClient.BatchWriteItemWithContext(ctx, input)
 }
 
+func (c *Client) TransactWriteItems(ctx context.Context, input *dynamodb.TransactWriteItemsInput) (*dynamodb.TransactWriteItemsOutput, error) {
+	return c.dynamodbClient.TransactWriteItemsWithContext(ctx, input)
+}
+
 func (c *Client) Query(ctx context.Context, input *dynamodb.QueryInput) (*dynamodb.QueryOutput, error) {
 	return c.dynamodbClient.QueryWithContext(ctx, input)
 }
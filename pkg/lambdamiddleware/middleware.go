@@ -0,0 +1,108 @@
+package lambdamiddleware
+
+import (
+	"context"
+	"net/http"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/jponc/estimatex-serverless/pkg/lambdaresponses"
+	"github.com/jponc/estimatex-serverless/pkg/logger"
+	log "github.com/sirupsen/logrus"
+)
+
+// Handler is an API Gateway proxy Lambda handler
+type Handler func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error)
+
+// Middleware wraps a Handler with behaviour that runs before and/or after it
+type Middleware func(next Handler) Handler
+
+// Chain wraps h with middlewares, the first middleware is the outermost
+func Chain(h Handler, middlewares ...Middleware) Handler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		h = middlewares[i](h)
+	}
+
+	return h
+}
+
+// Recover turns a panic in the handler into a 500 instead of crashing the Lambda
+func Recover(next Handler) Handler {
+	return func(ctx context.Context, request events.APIGatewayProxyRequest) (res events.APIGatewayProxyResponse, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				logger.FromContext(ctx).Errorf("recovered from panic: %v\n%s", r, debug.Stack())
+				res, err = lambdaresponses.Respond500()
+			}
+		}()
+
+		return next(ctx, request)
+	}
+}
+
+// CorrelationIDHeader lets a client pass its own correlation ID, the API Gateway request ID is
+// used otherwise
+const CorrelationIDHeader = "X-Correlation-ID"
+
+// Logging attaches the request ID and correlation ID to the context's logger, then logs every
+// request with its status code and how long it took
+func Logging(next Handler) Handler {
+	return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		start := time.Now()
+
+		correlationID := Header(request, CorrelationIDHeader)
+		if correlationID == "" {
+			correlationID = request.RequestContext.RequestID
+		}
+
+		ctx = logger.WithFields(ctx, log.Fields{logger.FieldRequestID: request.RequestContext.RequestID})
+		ctx = logger.WithCorrelationID(ctx, correlationID)
+
+		res, err := next(ctx, request)
+
+		logger.FromContext(ctx).WithFields(log.Fields{
+			"method":      request.HTTPMethod,
+			"path":        request.Path,
+			"status":      res.StatusCode,
+			"duration_ms": time.Since(start).Milliseconds(),
+		}).Info("handled request")
+
+		return res, err
+	}
+}
+
+// Header returns the request header key, API Gateway keeps the casing the client sent
+func Header(request events.APIGatewayProxyRequest, key string) string {
+	for k, v := range request.Headers {
+		if strings.EqualFold(k, key) {
+			return v
+		}
+	}
+
+	return ""
+}
+
+// CORS answers preflight requests and adds the policy's headers to every response, a nil policy
+// adds none so only same origin requests work
+func CORS(policy *lambdaresponses.CORSPolicy) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+			if policy == nil {
+				return next(ctx, request)
+			}
+
+			origin := lambdaresponses.RequestOrigin(request)
+
+			if request.HTTPMethod == http.MethodOptions {
+				return policy.RespondPreflight(origin)
+			}
+
+			res, err := next(ctx, request)
+			policy.Apply(&res, origin)
+
+			return res, err
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package lambdaresponses
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// CORSPolicy decides which browser origins may call the API. Allowed origins are echoed back
+// rather than answered with a wildcard, browsers reject "*" on credentialed requests.
+type CORSPolicy struct {
+	// AllowedOrigins are exact origins e.g. https://estimatex.io, "*" allows any origin but never with credentials
+	AllowedOrigins   []string
+	AllowCredentials bool
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	// ExposedHeaders are response headers scripts may read beyond the safelisted ones
+	ExposedHeaders []string
+	MaxAge         time.Duration
+}
+
+// NewCORSPolicy instantiates a credentialed policy for allowedOrigins with the methods and
+// headers our endpoints use
+func NewCORSPolicy(allowedOrigins []string) *CORSPolicy {
+	origins := []string{}
+	for _, o := range allowedOrigins {
+		o = strings.TrimRight(strings.TrimSpace(o), "/")
+		if o != "" {
+			origins = append(origins, o)
+		}
+	}
+
+	return &CORSPolicy{
+		AllowedOrigins:   origins,
+		AllowCredentials: true,
+		AllowedMethods:   []string{http.MethodGet, http.MethodPost, http.MethodOptions},
+		AllowedHeaders:   []string{"Authorization", "Content-Type", "Accept", "X-Correlation-ID"},
+		ExposedHeaders:   []string{"Retry-After", "Content-Disposition"},
+		MaxAge:           10 * time.Minute,
+	}
+}
+
+// Apply adds the CORS headers for origin to res
+func (p *CORSPolicy) Apply(res *events.APIGatewayProxyResponse, origin string) {
+	if res.Headers == nil {
+		res.Headers = map[string]string{}
+	}
+
+	// Responses differ per origin so caches must key on it
+	res.Headers["Vary"] = "Origin"
+
+	allowed := p.allowedOrigin(origin)
+	if allowed == "" {
+		return
+	}
+
+	res.Headers["Access-Control-Allow-Origin"] = allowed
+	if p.AllowCredentials && allowed != "*" {
+		res.Headers["Access-Control-Allow-Credentials"] = "true"
+	}
+	if len(p.ExposedHeaders) > 0 {
+		res.Headers["Access-Control-Expose-Headers"] = strings.Join(p.ExposedHeaders, ", ")
+	}
+}
+
+// RespondPreflight answers an OPTIONS preflight request from origin
+func (p *CORSPolicy) RespondPreflight(origin string) (events.APIGatewayProxyResponse, error) {
+	res := events.APIGatewayProxyResponse{
+		StatusCode: http.StatusNoContent,
+		Headers:    map[string]string{},
+	}
+
+	p.Apply(&res, origin)
+
+	if _, ok := res.Headers["Access-Control-Allow-Origin"]; ok {
+		res.Headers["Access-Control-Allow-Methods"] = strings.Join(p.AllowedMethods, ", ")
+		res.Headers["Access-Control-Allow-Headers"] = strings.Join(p.AllowedHeaders, ", ")
+		res.Headers["Access-Control-Max-Age"] = strconv.Itoa(int(p.MaxAge.Seconds()))
+	}
+
+	return res, nil
+}
+
+func (p *CORSPolicy) allowedOrigin(origin string) string {
+	if origin == "" {
+		return ""
+	}
+
+	for _, o := range p.AllowedOrigins {
+		if o == "*" {
+			return "*"
+		}
+		if o == origin {
+			return origin
+		}
+	}
+
+	return ""
+}
+
+// RequestOrigin returns the Origin header, API Gateway keeps the casing the client sent
+func RequestOrigin(request events.APIGatewayProxyRequest) string {
+	for k, v := range request.Headers {
+		if strings.EqualFold(k, "Origin") {
+			return v
+		}
+	}
+
+	return ""
+}
@@ -1,65 +1,131 @@
 package lambdaresponses
 
 import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
 	"github.com/aws/aws-lambda-go/events"
+	"github.com/jponc/estimatex-serverless/api/schema"
 	"gopkg.in/square/go-jose.v2/json"
 )
 
-type errorResponseBody struct {
-	Error string `json:"error"`
+// FieldError describes why a single request field was rejected
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
 }
 
-func Respond500() (events.APIGatewayProxyResponse, error) {
-	return events.APIGatewayProxyResponse{
-		Headers: map[string]string{
-			"Access-Control-Allow-Origin":      "*",
-			"Access-Control-Allow-Credentials": "true",
-		},
-		Body:       "Internal Server Error",
-		StatusCode: 500,
-	}, nil
+// APIError is the body of every error response. Code is stable and machine readable, Message is
+// kept under "error" for clients that only display it.
+type APIError struct {
+	Status    int          `json:"-"`
+	Code      string       `json:"code"`
+	Message   string       `json:"error"`
+	Details   []FieldError `json:"details,omitempty"`
+	RequestID string       `json:"request_id,omitempty"`
+	// RetryAfter is sent as the Retry-After header, rounded up to the second
+	RetryAfter time.Duration `json:"-"`
 }
 
-func Respond400(err error) (events.APIGatewayProxyResponse, error) {
-	resBody := errorResponseBody{
-		Error: err.Error(),
+// NewAPIError instantiates an APIError
+func NewAPIError(status int, code, message string) *APIError {
+	return &APIError{
+		Status:  status,
+		Code:    code,
+		Message: message,
 	}
+}
+
+func (e *APIError) Error() string {
+	return e.Message
+}
 
-	body, err := json.Marshal(resBody)
+// RespondError responds with e as the body and its status code
+func RespondError(e *APIError) (events.APIGatewayProxyResponse, error) {
+	body, err := json.Marshal(e)
 	if err != nil {
 		return Respond500()
 	}
 
+	h := headers()
+	if e.RetryAfter > 0 {
+		h["Retry-After"] = strconv.Itoa(int(math.Ceil(e.RetryAfter.Seconds())))
+	}
+
 	return events.APIGatewayProxyResponse{
-		Headers: map[string]string{
-			"Access-Control-Allow-Origin":      "*",
-			"Access-Control-Allow-Credentials": "true",
-		},
+		Headers:    h,
 		Body:       string(body),
-		StatusCode: 400,
+		StatusCode: e.Status,
 	}, nil
 }
 
-func Respond404(err error) (events.APIGatewayProxyResponse, error) {
-	resBody := errorResponseBody{
-		Error: err.Error(),
-	}
-
-	body, err := json.Marshal(resBody)
-	if err != nil {
-		return Respond500()
-	}
-
+func Respond500() (events.APIGatewayProxyResponse, error) {
 	return events.APIGatewayProxyResponse{
-		Headers: map[string]string{
-			"Access-Control-Allow-Origin":      "*",
-			"Access-Control-Allow-Credentials": "true",
-		},
-		Body:       string(body),
-		StatusCode: 404,
+		Headers:    headers(),
+		Body:       `{"code":"internal_error","error":"Internal Server Error"}`,
+		StatusCode: http.StatusInternalServerError,
 	}, nil
 }
 
+// Respond400 responds with err as a bad_request error
+func Respond400(requestID string, err error) (events.APIGatewayProxyResponse, error) {
+	return respondCode(requestID, http.StatusBadRequest, schema.ErrCodeBadRequest, err)
+}
+
+// Respond401 responds with err as an unauthorized error
+func Respond401(requestID string, err error) (events.APIGatewayProxyResponse, error) {
+	return respondCode(requestID, http.StatusUnauthorized, schema.ErrCodeUnauthorized, err)
+}
+
+// Respond403 responds with err as a not_allowed error
+func Respond403(requestID string, err error) (events.APIGatewayProxyResponse, error) {
+	return respondCode(requestID, http.StatusForbidden, schema.ErrCodeNotAllowed, err)
+}
+
+// Respond404 responds with err as a not_found error
+func Respond404(requestID string, err error) (events.APIGatewayProxyResponse, error) {
+	return respondCode(requestID, http.StatusNotFound, schema.ErrCodeNotFound, err)
+}
+
+// Respond409 responds with err as a conflict error
+func Respond409(requestID string, err error) (events.APIGatewayProxyResponse, error) {
+	return respondCode(requestID, http.StatusConflict, schema.ErrCodeConflict, err)
+}
+
+// Respond410 responds with err as a gone error
+func Respond410(requestID string, err error) (events.APIGatewayProxyResponse, error) {
+	return respondCode(requestID, http.StatusGone, schema.ErrCodeGone, err)
+}
+
+// Respond422 responds with err as a validation_failed error listing the invalid fields
+func Respond422(requestID string, err error, details []FieldError) (events.APIGatewayProxyResponse, error) {
+	e := NewAPIError(http.StatusUnprocessableEntity, schema.ErrCodeValidationFailed, err.Error())
+	e.Details = details
+	e.RequestID = requestID
+
+	return RespondError(e)
+}
+
+// Respond429 responds with err as a rate_limited error telling the client when to retry
+func Respond429(requestID string, err error, retryAfter time.Duration) (events.APIGatewayProxyResponse, error) {
+	e := NewAPIError(http.StatusTooManyRequests, schema.ErrCodeRateLimited, err.Error())
+	e.RetryAfter = retryAfter
+	e.RequestID = requestID
+
+	return RespondError(e)
+}
+
+func respondCode(requestID string, status int, code string, err error) (events.APIGatewayProxyResponse, error) {
+	e := NewAPIError(status, code, err.Error())
+	e.RequestID = requestID
+
+	return RespondError(e)
+}
+
 func Respond200(body interface{}) (events.APIGatewayProxyResponse, error) {
 	bodyJson, err := json.Marshal(body)
 	if err != nil {
@@ -67,22 +133,40 @@ func Respond200(body interface{}) (events.APIGatewayProxyResponse, error) {
 	}
 
 	return events.APIGatewayProxyResponse{
-		Headers: map[string]string{
-			"Access-Control-Allow-Origin":      "*",
-			"Access-Control-Allow-Credentials": "true",
-		},
+		Headers:    headers(),
 		Body:       string(bodyJson),
-		StatusCode: 200,
+		StatusCode: http.StatusOK,
+	}, nil
+}
+
+// RespondFile responds with body as a download named filename
+func RespondFile(contentType, filename string, body []byte) (events.APIGatewayProxyResponse, error) {
+	h := map[string]string{
+		"Content-Type":        contentType,
+		"Content-Disposition": fmt.Sprintf("attachment; filename=%q", filename),
+	}
+
+	return events.APIGatewayProxyResponse{
+		Headers:    h,
+		Body:       string(body),
+		StatusCode: http.StatusOK,
 	}, nil
 }
 
 func Respond302(location string) (events.APIGatewayProxyResponse, error) {
+	h := map[string]string{
+		"Location": location,
+	}
+
 	return events.APIGatewayProxyResponse{
-		StatusCode: 302,
-		Headers: map[string]string{
-			"Access-Control-Allow-Origin":      "*",
-			"Access-Control-Allow-Credentials": "true",
-			"Location":                         location,
-		},
+		StatusCode: http.StatusFound,
+		Headers:    h,
 	}, nil
 }
+
+// headers are the base headers of every response, CORS headers are added by CORSPolicy.Apply
+func headers() map[string]string {
+	return map[string]string{
+		"Content-Type": "application/json",
+	}
+}
@@ -0,0 +1,75 @@
+package lambdaresponses
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+func TestRespondHelpers(t *testing.T) {
+	errTest := errors.New("something is off")
+
+	tests := []struct {
+		name    string
+		respond func() (events.APIGatewayProxyResponse, error)
+		status  int
+		code    string
+	}{
+		{"400", func() (events.APIGatewayProxyResponse, error) { return Respond400("req-1", errTest) }, http.StatusBadRequest, "bad_request"},
+		{"401", func() (events.APIGatewayProxyResponse, error) { return Respond401("req-1", errTest) }, http.StatusUnauthorized, "unauthorized"},
+		{"403", func() (events.APIGatewayProxyResponse, error) { return Respond403("req-1", errTest) }, http.StatusForbidden, "not_allowed"},
+		{"404", func() (events.APIGatewayProxyResponse, error) { return Respond404("req-1", errTest) }, http.StatusNotFound, "not_found"},
+		{"409", func() (events.APIGatewayProxyResponse, error) { return Respond409("req-1", errTest) }, http.StatusConflict, "conflict"},
+		{"410", func() (events.APIGatewayProxyResponse, error) { return Respond410("req-1", errTest) }, http.StatusGone, "gone"},
+		{"422", func() (events.APIGatewayProxyResponse, error) { return Respond422("req-1", errTest, nil) }, http.StatusUnprocessableEntity, "validation_failed"},
+		{"429", func() (events.APIGatewayProxyResponse, error) { return Respond429("req-1", errTest, time.Second) }, http.StatusTooManyRequests, "rate_limited"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := tt.respond()
+			if err != nil {
+				t.Fatalf("respond: %v", err)
+			}
+
+			if res.StatusCode != tt.status {
+				t.Errorf("status = %d, want %d", res.StatusCode, tt.status)
+			}
+
+			var body APIError
+			if err := json.Unmarshal([]byte(res.Body), &body); err != nil {
+				t.Fatalf("unmarshal body: %v", err)
+			}
+			if body.Code != tt.code || body.Message != errTest.Error() || body.RequestID != "req-1" {
+				t.Errorf("body = %+v, want code %s with the error and request ID", body, tt.code)
+			}
+		})
+	}
+}
+
+func TestRespond429RetryAfter(t *testing.T) {
+	res, _ := Respond429("req-1", errors.New("too many requests"), 1500*time.Millisecond)
+
+	// Clients get whole seconds, rounded up so they don't retry too early
+	if got := res.Headers["Retry-After"]; got != "2" {
+		t.Errorf("Retry-After = %q, want 2", got)
+	}
+}
+
+func TestRespond422Details(t *testing.T) {
+	details := []FieldError{{Field: "vote", Code: "deck", Message: "vote must be one of the room's cards"}}
+
+	res, _ := Respond422("req-1", errors.New("invalid vote"), details)
+
+	var body APIError
+	if err := json.Unmarshal([]byte(res.Body), &body); err != nil {
+		t.Fatalf("unmarshal body: %v", err)
+	}
+	if len(body.Details) != 1 || body.Details[0] != details[0] {
+		t.Errorf("details = %+v, want %+v", body.Details, details)
+	}
+}
@@ -0,0 +1,84 @@
+package logger
+
+import (
+	"context"
+	"os"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Field names shared by every Lambda so logs can be queried across functions
+const (
+	FieldFunction      = "function"
+	FieldRequestID     = "request_id"
+	FieldCorrelationID = "correlation_id"
+	FieldRoomID        = "room_id"
+	FieldParticipant   = "participant"
+	FieldEventID       = "event_id"
+	FieldEventType     = "event_type"
+	FieldWebhookID     = "webhook_id"
+)
+
+type entryKey struct{}
+type correlationIDKey struct{}
+
+// Init switches the standard logger to JSON at the LOG_LEVEL environment level, info when unset
+// or invalid, and tags every entry with the Lambda function name
+func Init() {
+	log.SetFormatter(&log.JSONFormatter{})
+	log.SetOutput(os.Stdout)
+
+	level, err := log.ParseLevel(os.Getenv("LOG_LEVEL"))
+	if err != nil {
+		level = log.InfoLevel
+	}
+	log.SetLevel(level)
+
+	if name := os.Getenv("AWS_LAMBDA_FUNCTION_NAME"); name != "" {
+		log.AddHook(&functionHook{name: name})
+	}
+}
+
+// WithFields returns a copy of ctx whose logger carries fields on top of the ones already in ctx
+func WithFields(ctx context.Context, fields log.Fields) context.Context {
+	return context.WithValue(ctx, entryKey{}, FromContext(ctx).WithFields(fields))
+}
+
+// FromContext returns the logger stored in ctx, or the standard logger when there's none
+func FromContext(ctx context.Context) *log.Entry {
+	if entry, ok := ctx.Value(entryKey{}).(*log.Entry); ok {
+		return entry
+	}
+
+	return log.NewEntry(log.StandardLogger())
+}
+
+// WithCorrelationID stores the ID linking a request to the events it triggers and adds it to the
+// context's logger
+func WithCorrelationID(ctx context.Context, correlationID string) context.Context {
+	ctx = context.WithValue(ctx, correlationIDKey{}, correlationID)
+	return WithFields(ctx, log.Fields{FieldCorrelationID: correlationID})
+}
+
+// CorrelationID returns the correlation ID stored in ctx, empty when there's none
+func CorrelationID(ctx context.Context) string {
+	id, _ := ctx.Value(correlationIDKey{}).(string)
+	return id
+}
+
+// functionHook adds the function name to entries without one
+type functionHook struct {
+	name string
+}
+
+func (h *functionHook) Levels() []log.Level {
+	return log.AllLevels
+}
+
+func (h *functionHook) Fire(entry *log.Entry) error {
+	if _, ok := entry.Data[FieldFunction]; !ok {
+		entry.Data[FieldFunction] = h.name
+	}
+
+	return nil
+}
@@ -0,0 +1,128 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-lambda-go/lambdacontext"
+	"github.com/jponc/estimatex-serverless/pkg/logger"
+)
+
+// Units CloudWatch accepts for the metrics we emit
+const (
+	UnitCount        = "Count"
+	UnitSeconds      = "Seconds"
+	UnitMilliseconds = "Milliseconds"
+)
+
+// Metric is a single named value
+type Metric struct {
+	Name  string
+	Unit  string
+	Value float64
+}
+
+// Count is a Metric counting n things
+func Count(name string, n int) Metric {
+	return Metric{Name: name, Unit: UnitCount, Value: float64(n)}
+}
+
+// Duration is a Metric in milliseconds
+func Duration(name string, d time.Duration) Metric {
+	return Metric{Name: name, Unit: UnitMilliseconds, Value: float64(d.Milliseconds())}
+}
+
+// Seconds is a Metric in seconds
+func Seconds(name string, d time.Duration) Metric {
+	return Metric{Name: name, Unit: UnitSeconds, Value: d.Seconds()}
+}
+
+// Recorder writes metrics as CloudWatch Embedded Metric Format JSON lines, CloudWatch extracts
+// them from the Lambda logs so publishing costs no API calls. A nil Recorder records nothing.
+type Recorder struct {
+	namespace  string
+	dimensions map[string]string
+	out        io.Writer
+	mu         sync.Mutex
+	now        func() time.Time
+}
+
+// NewRecorder instantiates a recorder writing to out, stdout when nil. Metrics are dimensioned by
+// the Lambda function name when running in Lambda.
+func NewRecorder(namespace string, out io.Writer) *Recorder {
+	if out == nil {
+		out = os.Stdout
+	}
+
+	dimensions := map[string]string{}
+	if lambdacontext.FunctionName != "" {
+		dimensions["Function"] = lambdacontext.FunctionName
+	}
+
+	return &Recorder{
+		namespace:  namespace,
+		dimensions: dimensions,
+		out:        out,
+		now:        time.Now,
+	}
+}
+
+// Record writes one EMF line holding metrics
+func (r *Recorder) Record(metrics ...Metric) error {
+	if r == nil || len(metrics) == 0 {
+		return nil
+	}
+
+	dimensionNames := []string{}
+	line := map[string]interface{}{}
+	for k, v := range r.dimensions {
+		dimensionNames = append(dimensionNames, k)
+		line[k] = v
+	}
+
+	definitions := []map[string]string{}
+	for _, m := range metrics {
+		definitions = append(definitions, map[string]string{"Name": m.Name, "Unit": m.Unit})
+		line[m.Name] = m.Value
+	}
+
+	line["_aws"] = map[string]interface{}{
+		"Timestamp": r.now().UnixNano() / int64(time.Millisecond),
+		"CloudWatchMetrics": []map[string]interface{}{
+			{
+				"Namespace":  r.namespace,
+				"Dimensions": [][]string{dimensionNames},
+				"Metrics":    definitions,
+			},
+		},
+	}
+
+	b, err := json.Marshal(line)
+	if err != nil {
+		return fmt.Errorf("failed to marshal metrics: %v", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, err = r.out.Write(append(b, '\n'))
+	if err != nil {
+		return fmt.Errorf("failed to write metrics: %v", err)
+	}
+
+	return nil
+}
+
+// Emit records metrics like Record but only logs a failure, metrics mustn't fail the work they
+// measure
+func (r *Recorder) Emit(ctx context.Context, metrics ...Metric) {
+	err := r.Record(metrics...)
+	if err != nil {
+		logger.FromContext(ctx).Warnf("failed to record metrics: %v", err)
+	}
+}
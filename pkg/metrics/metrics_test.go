@@ -0,0 +1,153 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/lambdacontext"
+)
+
+type emfLine struct {
+	AWS struct {
+		Timestamp         int64 `json:"Timestamp"`
+		CloudWatchMetrics []struct {
+			Namespace  string              `json:"Namespace"`
+			Dimensions [][]string          `json:"Dimensions"`
+			Metrics    []map[string]string `json:"Metrics"`
+		} `json:"CloudWatchMetrics"`
+	} `json:"_aws"`
+}
+
+func newTestRecorder(t *testing.T, functionName string) (*Recorder, *bytes.Buffer) {
+	t.Helper()
+
+	previous := lambdacontext.FunctionName
+	lambdacontext.FunctionName = functionName
+	t.Cleanup(func() { lambdacontext.FunctionName = previous })
+
+	out := &bytes.Buffer{}
+	r := NewRecorder("EstimateX", out)
+	r.now = func() time.Time { return time.Unix(1600000000, 0) }
+
+	return r, out
+}
+
+func TestRecord(t *testing.T) {
+	r, out := newTestRecorder(t, "estimatex-CastVote")
+
+	err := r.Record(Count("VotesCast", 1), Duration("VoteLatency", 1500*time.Millisecond))
+	if err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+
+	if !strings.HasSuffix(out.String(), "}\n") || strings.Count(out.String(), "\n") != 1 {
+		t.Fatalf("output = %q, want one JSON line", out.String())
+	}
+
+	var line emfLine
+	if err := json.Unmarshal(out.Bytes(), &line); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	if line.AWS.Timestamp != 1600000000000 {
+		t.Errorf("timestamp = %d, want milliseconds since the epoch", line.AWS.Timestamp)
+	}
+	if len(line.AWS.CloudWatchMetrics) != 1 {
+		t.Fatalf("got %d metric directives, want 1", len(line.AWS.CloudWatchMetrics))
+	}
+
+	directive := line.AWS.CloudWatchMetrics[0]
+	if directive.Namespace != "EstimateX" {
+		t.Errorf("namespace = %q, want EstimateX", directive.Namespace)
+	}
+	if want := [][]string{{"Function"}}; !reflect.DeepEqual(directive.Dimensions, want) {
+		t.Errorf("dimensions = %v, want %v", directive.Dimensions, want)
+	}
+
+	wantMetrics := []map[string]string{
+		{"Name": "VotesCast", "Unit": UnitCount},
+		{"Name": "VoteLatency", "Unit": UnitMilliseconds},
+	}
+	if !reflect.DeepEqual(directive.Metrics, wantMetrics) {
+		t.Errorf("metrics = %v, want %v", directive.Metrics, wantMetrics)
+	}
+
+	// Dimension and metric values sit at the root of the line
+	values := map[string]interface{}{}
+	if err := json.Unmarshal(out.Bytes(), &values); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if values["Function"] != "estimatex-CastVote" {
+		t.Errorf("Function = %v, want estimatex-CastVote", values["Function"])
+	}
+	if values["VotesCast"] != 1.0 {
+		t.Errorf("VotesCast = %v, want 1", values["VotesCast"])
+	}
+	if values["VoteLatency"] != 1500.0 {
+		t.Errorf("VoteLatency = %v, want 1500", values["VoteLatency"])
+	}
+}
+
+func TestRecordOutsideLambda(t *testing.T) {
+	r, out := newTestRecorder(t, "")
+
+	err := r.Record(Seconds("RoundDuration", 90*time.Second))
+	if err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+
+	var line emfLine
+	if err := json.Unmarshal(out.Bytes(), &line); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	// CloudWatch needs a dimension set even when it's empty
+	if want := [][]string{{}}; !reflect.DeepEqual(line.AWS.CloudWatchMetrics[0].Dimensions, want) {
+		t.Errorf("dimensions = %v, want %v", line.AWS.CloudWatchMetrics[0].Dimensions, want)
+	}
+	if !strings.Contains(out.String(), `"RoundDuration":90`) {
+		t.Errorf("output = %s, want RoundDuration of 90 seconds", out.String())
+	}
+}
+
+func TestRecordNothing(t *testing.T) {
+	var nilRecorder *Recorder
+	if err := nilRecorder.Record(Count("VotesCast", 1)); err != nil {
+		t.Errorf("nil Recorder: %v", err)
+	}
+
+	r, out := newTestRecorder(t, "")
+	if err := r.Record(); err != nil {
+		t.Errorf("Record: %v", err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("output = %q, want nothing written without metrics", out.String())
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("closed")
+}
+
+func TestEmit(t *testing.T) {
+	var nilRecorder *Recorder
+	nilRecorder.Emit(context.Background(), Count("VotesCast", 1))
+
+	r, out := newTestRecorder(t, "")
+	r.Emit(context.Background(), Count("VotesCast", 1))
+	if !strings.Contains(out.String(), `"VotesCast":1`) {
+		t.Errorf("output = %s, want VotesCast of 1", out.String())
+	}
+
+	// A failed write is only logged
+	r.out = failingWriter{}
+	r.Emit(context.Background(), Count("VotesCast", 1))
+}
@@ -10,7 +10,7 @@ import (
 	"github.com/aws/aws-sdk-go/service/sns"
 	awsSns "github.com/aws/aws-sdk-go/service/sns"
 	"github.com/aws/aws-xray-sdk-go/xray"
-	log "github.com/sirupsen/logrus"
+	"github.com/jponc/estimatex-serverless/pkg/logger"
 )
 
 type Client struct {
@@ -40,6 +40,11 @@ func NewClient(awsRegion, snsPrefix string) (*Client, error) {
 }
 
 func (c *Client) Publish(ctx context.Context, topic string, message interface{}) error {
+	return c.PublishWithAttributes(ctx, topic, message, nil)
+}
+
+// PublishWithAttributes publishes message with string message attributes, empty values are dropped
+func (c *Client) PublishWithAttributes(ctx context.Context, topic string, message interface{}, attributes map[string]string) error {
 	msg, err := json.Marshal(message)
 	if err != nil {
 		return fmt.Errorf("failed to marshal sns message: %v", err)
@@ -50,11 +55,26 @@ func (c *Client) Publish(ctx context.Context, topic string, message interface{})
 		TopicArn: aws.String(fmt.Sprintf("%s-%s", c.snsPrefix, topic)),
 	}
 
+	for k, v := range attributes {
+		if v == "" {
+			continue
+		}
+
+		if input.MessageAttributes == nil {
+			input.MessageAttributes = map[string]*awsSns.MessageAttributeValue{}
+		}
+
+		input.MessageAttributes[k] = &awsSns.MessageAttributeValue{
+			DataType:    aws.String("String"),
+			StringValue: aws.String(v),
+		}
+	}
+
 	result, err := c.awsSnsClient.PublishWithContext(ctx, input)
 	if err != nil {
 		return fmt.Errorf("failed to publish to sns: %v", err)
 	}
 
-	log.Infof("successfully processed sns message: (%s) for topic (%s)", result, topic)
+	logger.FromContext(ctx).Debugf("published sns message (%s) to topic (%s)", aws.StringValue(result.MessageId), topic)
 	return nil
 }
@@ -0,0 +1,77 @@
+package ssm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/session"
+	awsSsm "github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/aws/aws-xray-sdk-go/xray"
+)
+
+// ErrNotFound is returned when a parameter doesn't exist
+var ErrNotFound = errors.New("parameter not found")
+
+type Client struct {
+	awsSsmClient *awsSsm.SSM
+}
+
+// NewClient instantiates a SSM Parameter Store client
+func NewClient(awsRegion string) (*Client, error) {
+	sess, err := session.NewSession(&aws.Config{
+		Region: aws.String(awsRegion),
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("cannot create aws session: %v", err)
+	}
+
+	awsSsmClient := awsSsm.New(sess)
+	xray.AWS(awsSsmClient.Client)
+
+	c := &Client{
+		awsSsmClient: awsSsmClient,
+	}
+
+	return c, nil
+}
+
+// GetSecret returns the decrypted value of a SecureString parameter
+func (c *Client) GetSecret(ctx context.Context, name string) (string, error) {
+	input := &awsSsm.GetParameterInput{
+		Name:           aws.String(name),
+		WithDecryption: aws.Bool(true),
+	}
+
+	output, err := c.awsSsmClient.GetParameterWithContext(ctx, input)
+	if err != nil {
+		var aerr awserr.Error
+		if errors.As(err, &aerr) && aerr.Code() == awsSsm.ErrCodeParameterNotFound {
+			return "", ErrNotFound
+		}
+
+		return "", fmt.Errorf("failed to get parameter: %v", err)
+	}
+
+	return aws.StringValue(output.Parameter.Value), nil
+}
+
+// PutSecret stores value as a SecureString parameter, replacing any previous value
+func (c *Client) PutSecret(ctx context.Context, name, value string) error {
+	input := &awsSsm.PutParameterInput{
+		Name:      aws.String(name),
+		Value:     aws.String(value),
+		Type:      aws.String(awsSsm.ParameterTypeSecureString),
+		Overwrite: aws.Bool(true),
+	}
+
+	_, err := c.awsSsmClient.PutParameterWithContext(ctx, input)
+	if err != nil {
+		return fmt.Errorf("failed to put parameter: %v", err)
+	}
+
+	return nil
+}
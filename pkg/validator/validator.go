@@ -0,0 +1,166 @@
+package validator
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// FieldError describes a single field failing one of its rules
+type FieldError struct {
+	Field   string
+	Rule    string
+	Message string
+}
+
+// Errors aggregates every field that failed validation
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	messages := []string{}
+	for _, f := range e {
+		messages = append(messages, f.Message)
+	}
+
+	return strings.Join(messages, "; ")
+}
+
+// Validator checks structs against their `validate` tags. Supported rules, applied in order:
+//
+//	trim       trims surrounding whitespace from a string in place
+//	required   string or slice can't be empty
+//	min=N      minimum string length in characters, or slice length
+//	max=N      maximum string length in characters, or slice length
+//	pattern=X  string must match the pattern registered as X, empty strings are skipped
+type Validator struct {
+	patterns map[string]*regexp.Regexp
+}
+
+// New instantiates a Validator with the named patterns available to the pattern rule
+func New(patterns map[string]*regexp.Regexp) *Validator {
+	return &Validator{
+		patterns: patterns,
+	}
+}
+
+// Validate checks every field of the struct v points to. It returns Errors when fields are
+// invalid, any other error means the tags themselves are wrong.
+func (v *Validator) Validate(s interface{}) error {
+	rv := reflect.ValueOf(s)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("validator: expected pointer to struct, got %T", s)
+	}
+
+	rv = rv.Elem()
+	rt := rv.Type()
+	errs := Errors{}
+
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		tag, ok := sf.Tag.Lookup("validate")
+		if !ok || tag == "" {
+			continue
+		}
+
+		fieldErr, err := v.validateField(fieldName(sf), rv.Field(i), strings.Split(tag, ","))
+		if err != nil {
+			return fmt.Errorf("validator: %s.%s: %v", rt.Name(), sf.Name, err)
+		}
+
+		if fieldErr != nil {
+			errs = append(errs, *fieldErr)
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// validateField stops at the first failing rule so each field reports a single error
+func (v *Validator) validateField(name string, f reflect.Value, rules []string) (*FieldError, error) {
+	for _, rule := range rules {
+		ruleName, arg := rule, ""
+		if i := strings.Index(rule, "="); i >= 0 {
+			ruleName, arg = rule[:i], rule[i+1:]
+		}
+
+		switch ruleName {
+		case "trim":
+			if f.Kind() != reflect.String {
+				return nil, fmt.Errorf("trim needs a string")
+			}
+			f.SetString(strings.TrimSpace(f.String()))
+
+		case "required":
+			n, err := length(f)
+			if err != nil {
+				return nil, err
+			}
+			if n == 0 {
+				return &FieldError{Field: name, Rule: ruleName, Message: fmt.Sprintf("%s can't be blank", name)}, nil
+			}
+
+		case "min", "max":
+			limit, err := strconv.Atoi(arg)
+			if err != nil {
+				return nil, fmt.Errorf("%s needs an integer", ruleName)
+			}
+
+			n, err := length(f)
+			if err != nil {
+				return nil, err
+			}
+
+			if ruleName == "min" && n < limit {
+				return &FieldError{Field: name, Rule: ruleName, Message: fmt.Sprintf("%s must be at least %d long", name, limit)}, nil
+			}
+			if ruleName == "max" && n > limit {
+				return &FieldError{Field: name, Rule: ruleName, Message: fmt.Sprintf("%s must be at most %d long", name, limit)}, nil
+			}
+
+		case "pattern":
+			re, ok := v.patterns[arg]
+			if !ok {
+				return nil, fmt.Errorf("unknown pattern %s", arg)
+			}
+			if f.Kind() != reflect.String {
+				return nil, fmt.Errorf("pattern needs a string")
+			}
+			if f.String() != "" && !re.MatchString(f.String()) {
+				return &FieldError{Field: name, Rule: ruleName, Message: fmt.Sprintf("%s has an invalid format", name)}, nil
+			}
+
+		default:
+			return nil, fmt.Errorf("unknown rule %s", ruleName)
+		}
+	}
+
+	return nil, nil
+}
+
+func length(f reflect.Value) (int, error) {
+	switch f.Kind() {
+	case reflect.String:
+		return utf8.RuneCountInString(f.String()), nil
+	case reflect.Slice, reflect.Map:
+		return f.Len(), nil
+	default:
+		return 0, fmt.Errorf("length rules need a string, slice or map")
+	}
+}
+
+// fieldName reports fields by their JSON name so errors match the request body
+func fieldName(sf reflect.StructField) string {
+	name := strings.Split(sf.Tag.Get("json"), ",")[0]
+	if name == "" || name == "-" {
+		return sf.Name
+	}
+
+	return name
+}
@@ -4,6 +4,9 @@ resource "aws_dynamodb_table" "estimatex_table" {
   hash_key     = "PK"
   range_key    = "SK"
 
+  stream_enabled   = true
+  stream_view_type = "NEW_IMAGE"
+
   attribute {
     name = "PK"
     type = "S"
@@ -14,6 +17,29 @@ resource "aws_dynamodb_table" "estimatex_table" {
     type = "S"
   }
 
+  # GSI1 lists the sessions and revealed rounds of a team under GSI1PK Team_<slug>
+  attribute {
+    name = "GSI1PK"
+    type = "S"
+  }
+
+  attribute {
+    name = "GSI1SK"
+    type = "S"
+  }
+
+  global_secondary_index {
+    name            = "GSI1"
+    hash_key        = "GSI1PK"
+    range_key       = "GSI1SK"
+    projection_type = "ALL"
+  }
+
+  ttl {
+    attribute_name = "TTL"
+    enabled        = true
+  }
+
   tags = {
     Environment = "${var.environment}"
   }
@@ -3,3 +3,9 @@ resource "aws_ssm_parameter" "dynamodb_table" {
   type  = "String"
   value = aws_dynamodb_table.estimatex_table.id
 }
+
+resource "aws_ssm_parameter" "dynamodb_stream_arn" {
+  name  = "/${var.project_name}/${var.environment}/DYNAMODB_STREAM_ARN"
+  type  = "String"
+  value = aws_dynamodb_table.estimatex_table.stream_arn
+}